@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"git-log/config"
+	"git-log/internal/github"
+	"git-log/internal/report"
+)
+
+// checkCredentials makes one cheap call with the GitHub token and one with the LLM key,
+// so a bad token shows up before a long run rather than at the end of it
+func checkCredentials(config *config.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	githubErr := checkGitHubToken(ctx, config)
+	llmErr := checkProvider(ctx, config)
+
+	if githubErr != nil {
+		return apiError(githubErr)
+	}
+	if llmErr != nil {
+		return llmError(llmErr)
+	}
+	return nil
+}
+
+func checkGitHubToken(ctx context.Context, config *config.Config) error {
+	if config.GitHubToken == "" {
+		fmt.Println("GitHub:     skipped, no ACCESS_TOKEN and the work log is read from a file")
+		return nil
+	}
+
+	info, err := github.NewClient(config.GitHubToken).GetTokenInfo(ctx)
+	if err != nil {
+		fmt.Println("GitHub:     failed")
+		return fmt.Errorf("checking GitHub token: %w", err)
+	}
+
+	if !info.Classic {
+		fmt.Printf("GitHub:     authenticated as %s with a fine-grained token; it only sees the repositories it was granted\n", info.Login)
+	} else {
+		fmt.Printf("GitHub:     authenticated as %s with scopes: %s\n", info.Login, valueOr(strings.Join(info.Scopes, ", "), "none"))
+		if !slices.Contains(info.Scopes, "repo") {
+			fmt.Println("Warning: the token lacks the repo scope, so activity in private repositories won't be found")
+		}
+	}
+
+	if !strings.EqualFold(info.Login, config.Username) && !slices.ContainsFunc(config.Identities, func(identity string) bool {
+		return strings.EqualFold(identity, info.Login)
+	}) {
+		fmt.Printf("Warning: the token belongs to %s, not %s, so only activity visible to %s is found\n", info.Login, config.Username, info.Login)
+	}
+	return nil
+}
+
+func checkProvider(ctx context.Context, config *config.Config) error {
+	if config.Renderer == "template" {
+		fmt.Println("LLM:        skipped, the template renderer doesn't call a model")
+		return nil
+	}
+
+	provider, err := newProvider(ctx, config)
+	if err != nil {
+		fmt.Println("LLM:        failed")
+		return err
+	}
+
+	// A one-word answer costs a handful of tokens
+	if _, err := provider.Generate(ctx, "Reply with the single word OK.", "OK?", report.GenerateOptions{}); err != nil {
+		fmt.Println("LLM:        failed")
+		return fmt.Errorf("calling %s model %s: %w", config.Provider, config.Model, err)
+	}
+	fmt.Printf("LLM:        %s model %s answered\n", config.Provider, config.Model)
+	return nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"git-log/config"
+	"git-log/internal/github"
+	"git-log/internal/processing"
+	"git-log/internal/report"
+	"git-log/internal/store"
+)
+
+// loadWorkLog builds the work log of the configured window, replaying a saved one when
+// WORK_LOG_PATH is set. The client is nil for a replay. until is the end of the window.
+func loadWorkLog(ctx context.Context, config *config.Config, reportProfiles []report.Profile) (*processing.WorkLog, *github.Client, time.Time, error) {
+	if config.WorkLogPath != "" {
+		fmt.Printf("Loading work log from %s...\n", config.WorkLogPath)
+		workLog, err := processing.LoadWorkLog(config.WorkLogPath)
+		if err != nil {
+			return nil, nil, time.Time{}, err
+		}
+		// The report covers the saved window, not the time of the replay
+		return filterRepositories(config, workLog), nil, workLog.Summary.Period.End, nil
+	}
+
+	client := github.NewClient(config.GitHubToken)
+	if !config.NoCache {
+		client.Cache = github.NewCache(config.CacheDir, config.CacheTTL)
+	}
+
+	workLog, until, err := fetchWorkLog(ctx, config, client, reportProfiles)
+	if err != nil {
+		return nil, nil, until, apiError(err)
+	}
+	return workLog, client, until, nil
+}
+
+// fetchWorkLog fetches the activity of the configured window from GitHub and groups it into a work log
+func fetchWorkLog(ctx context.Context, config *config.Config, client *github.Client, reportProfiles []report.Profile) (*processing.WorkLog, time.Time, error) {
+	since, until := config.Since, config.Until
+	if config.AutoSince {
+		// The first profile's output decides where the window continues from
+		var err error
+		since, err = resolveSince(profileOutputPath(config.ReportPath, reportProfiles[0]), until)
+		if err != nil {
+			return nil, until, fmt.Errorf("resolving lookback window: %w", err)
+		}
+		since = since.In(config.Location)
+	}
+
+	fmt.Printf("Fetching GitHub activity from %s to %s...\n",
+		since.Format("Jan 2, 2006"), until.Format("Jan 2, 2006"))
+
+	activity := &fetchedActivity{}
+	for i, username := range append([]string{config.Username}, config.Identities...) {
+		if i > 0 {
+			fmt.Printf("Fetching activity of %s...\n", username)
+		}
+
+		var fetched *fetchedActivity
+		var err error
+		if config.StoreDir != "" {
+			// Each identity keeps its own store, since sync state is tracked per source
+			storeDir := config.StoreDir
+			if i > 0 {
+				storeDir = filepath.Join(storeDir, "identities", username)
+			}
+			fetched, err = fetchWithStore(ctx, client, storeDir, username, since, until)
+		} else {
+			// Round an open-ended window up to the end of the day so the query, and its cache key, stays the same all day
+			searchUntil := until
+			if config.OpenEnded {
+				y, m, d := until.Date()
+				searchUntil = time.Date(y, m, d+1, 0, 0, 0, 0, until.Location()).Add(-time.Second)
+			}
+			fetched, err = fetch(ctx, client, username, since, searchUntil)
+		}
+		if err != nil {
+			return nil, until, err
+		}
+		activity.merge(fetched)
+	}
+
+	fmt.Printf("Found %d pull requests, %d commits and %d reviews\n",
+		len(activity.pullRequests), len(activity.commits), len(activity.reviews))
+
+	// Process and group data
+	fmt.Println("Processing activity data...")
+	workLog := processing.GroupByRepository(activity.pullRequests, activity.commits, activity.reviews, processing.Options{
+		Period:   processing.DateRange{Start: since, End: until},
+		Location: config.Location,
+	})
+
+	return filterRepositories(config, workLog), until, nil
+}
+
+// filterRepositories applies the configured repository filters, if any
+func filterRepositories(config *config.Config, workLog *processing.WorkLog) *processing.WorkLog {
+	if len(config.Repos) == 0 && len(config.ExcludeRepos) == 0 {
+		return workLog
+	}
+	return processing.FilterRepositories(workLog, processing.RepoFilter{Include: config.Repos, Exclude: config.ExcludeRepos})
+}
+
+// resolveSince picks up from when the report was last updated, falling back to
+// the default lookback for a report without history.
+func resolveSince(reportPath string, until time.Time) (time.Time, error) {
+	lastUpdated, err := report.LastUpdated(reportPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if lastUpdated.IsZero() || !lastUpdated.Before(until) {
+		fmt.Printf("No report history found, looking back %d days\n", config.DefaultLookbackDays)
+		return until.AddDate(0, 0, -config.DefaultLookbackDays), nil
+	}
+
+	fmt.Printf("Report last updated %s\n", lastUpdated.Format("Jan 2, 2006 15:04 MST"))
+	return lastUpdated, nil
+}
+
+// fetchedActivity holds the raw GitHub activity for the reporting window
+type fetchedActivity struct {
+	pullRequests []github.IssueSearchResultItem
+	commits      []github.CommitSearchResultItem
+	reviews      []github.IssueSearchResultItem
+}
+
+// merge adds the activity of another identity, skipping what was already fetched,
+// such as a commit both accounts are credited with
+func (a *fetchedActivity) merge(other *fetchedActivity) {
+	a.pullRequests = appendNewIssues(a.pullRequests, other.pullRequests)
+	a.reviews = appendNewIssues(a.reviews, other.reviews)
+
+	seen := make(map[string]bool, len(a.commits))
+	for _, commit := range a.commits {
+		seen[commit.SHA] = true
+	}
+	for _, commit := range other.commits {
+		if !seen[commit.SHA] {
+			seen[commit.SHA] = true
+			a.commits = append(a.commits, commit)
+		}
+	}
+}
+
+func appendNewIssues(items, other []github.IssueSearchResultItem) []github.IssueSearchResultItem {
+	seen := make(map[int64]bool, len(items))
+	for _, item := range items {
+		seen[item.ID] = true
+	}
+	for _, item := range other {
+		if !seen[item.ID] {
+			seen[item.ID] = true
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// fetch retrieves the whole window directly from GitHub
+func fetch(ctx context.Context, client *github.Client, username string, since, until time.Time) (*fetchedActivity, error) {
+	commits, err := client.GetCommits(ctx, username, since, until)
+	if err != nil {
+		fmt.Printf("Warning: Failed to fetch commits: %v\n", err)
+		fmt.Println("Continuing with pull requests only...")
+		commits = []github.CommitSearchResultItem{}
+	}
+
+	pullRequests, err := client.GetPullRequests(ctx, username, since, until)
+	if err != nil {
+		fmt.Printf("Error getting pull requests: %v\n", err)
+		return nil, fmt.Errorf("fetching pull requests: %w", err)
+	}
+
+	reviews, err := client.GetReviewedPullRequests(ctx, username, since, until)
+	if err != nil {
+		fmt.Printf("Warning: Failed to fetch reviews: %v\n", err)
+		reviews = []github.IssueSearchResultItem{}
+	}
+
+	return &fetchedActivity{pullRequests: pullRequests, commits: commits, reviews: reviews}, nil
+}
+
+// fetchWithStore syncs only what the store is missing and rebuilds the window from stored history
+func fetchWithStore(ctx context.Context, client *github.Client, storeDir, username string, since, until time.Time) (*fetchedActivity, error) {
+	st, err := store.Open(storeDir)
+	if err != nil {
+		return nil, fmt.Errorf("opening store: %w", err)
+	}
+
+	added, err := st.SyncCommits(ctx, client, username, since, until)
+	if err != nil {
+		fmt.Printf("Warning: Failed to sync commits: %v\n", err)
+		fmt.Println("Continuing with stored commits...")
+	} else {
+		fmt.Printf("Synced %d new commits\n", added)
+	}
+
+	added, err = st.SyncPullRequests(ctx, client, username, since, until)
+	if err != nil {
+		fmt.Printf("Error syncing pull requests: %v\n", err)
+		return nil, fmt.Errorf("syncing pull requests: %w", err)
+	}
+	fmt.Printf("Synced %d new pull requests\n", added)
+
+	added, err = st.SyncReviews(ctx, client, username, since, until)
+	if err != nil {
+		fmt.Printf("Warning: Failed to sync reviews: %v\n", err)
+	} else {
+		fmt.Printf("Synced %d new reviews\n", added)
+	}
+
+	if err := st.Save(); err != nil {
+		return nil, fmt.Errorf("saving store: %w", err)
+	}
+
+	return &fetchedActivity{
+		pullRequests: st.PullRequests(since, until),
+		commits:      st.Commits(since, until),
+		reviews:      st.Reviews(since, until),
+	}, nil
+}
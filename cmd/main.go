@@ -2,83 +2,459 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"git-log/config"
-	"git-log/internal/github"
+	"git-log/internal/export"
 	"git-log/internal/processing"
 	"git-log/internal/report"
 )
 
+// The binary is built without cgo for a minimal image that has no zoneinfo, so TIMEZONE needs the embedded copy
+import _ "time/tzdata"
+
+// Exit codes tell scripts what kind of failure stopped the run
+const (
+	exitError       = 1
+	exitConfigError = 2
+	exitAPIError    = 3
+	exitLLMError    = 4
+)
+
+// commandError attaches an exit code to an error
+type commandError struct {
+	code int
+	err  error
+}
+
+func (e *commandError) Error() string { return e.err.Error() }
+func (e *commandError) Unwrap() error { return e.err }
+
+func configError(err error) error { return &commandError{code: exitConfigError, err: err} }
+func apiError(err error) error    { return &commandError{code: exitAPIError, err: err} }
+func llmError(err error) error    { return &commandError{code: exitLLMError, err: err} }
+
+// settingFlag is a command line flag that overrides an environment variable
+type settingFlag struct {
+	env     string
+	usage   string
+	boolean bool
+}
+
+// settingFlags are every flag a command can accept, by name
+var settingFlags = map[string]settingFlag{
+	"config":         {env: "CONFIG_FILE", usage: "config file to read instead of .git-log.yaml or ~/.config/git-log/config.yaml"},
+	"config-profile": {env: "CONFIG_PROFILE", usage: "named profile of the config file to apply"},
+	"username":       {env: "USERNAME", usage: "GitHub username whose activity is reported"},
+	"identities":     {env: "IDENTITIES", usage: "comma-separated other GitHub usernames of the same person"},
+	"repos":          {env: "REPOS", usage: "comma-separated repositories to include, e.g. acme/*"},
+	"exclude-repos":  {env: "EXCLUDE_REPOS", usage: "comma-separated repositories to leave out"},
+	"period":         {env: "PERIOD", usage: "named reporting period, e.g. last-month or 2025-Q3"},
+	"since":          {env: "SINCE", usage: "start of the window, YYYY-MM-DD"},
+	"until":          {env: "UNTIL", usage: "end of the window, YYYY-MM-DD"},
+	"timezone":       {env: "TIMEZONE", usage: "IANA timezone for window boundaries and dates"},
+	"store-dir":      {env: "STORE_DIR", usage: "directory of the persistent activity store"},
+	"no-cache":       {env: "NO_CACHE", usage: "bypass the on-disk GitHub response cache", boolean: true},
+	"work-log":       {env: "WORK_LOG_PATH", usage: "use a saved work log JSON file instead of GitHub"},
+	"provider":       {env: "PROVIDER", usage: "LLM provider: gemini, openai, anthropic or ollama"},
+	"model":          {env: "MODEL", usage: "model used by the provider"},
+	"report-path":    {env: "REPORT_PATH", usage: "where the report is written"},
+	"renderer":       {env: "RENDERER", usage: "how the report is written: llm or template"},
+	"profiles":       {env: "PROFILES", usage: "comma-separated report profiles to write"},
+	"force":          {env: "FORCE_WRITE", usage: "write the report even if it lost content compared to the previous one", boolean: true},
+	"yes":            {env: "AUTO_ACCEPT", usage: "write reports without asking for confirmation", boolean: true},
+	"summarize":      {env: "SUMMARIZE", usage: "summarize each pull request once and write reports from the summaries", boolean: true},
+	"dry-run":        {env: "DRY_RUN", usage: "write the work log and prompts instead of calling the LLM", boolean: true},
+	"dry-run-dir":    {env: "DRY_RUN_DIR", usage: "directory for dry run output; empty prints it"},
+	"export":         {env: "EXPORT_ONLY", usage: "only write the work log as data files, without reports", boolean: true},
+	"export-dir":     {env: "EXPORT_DIR", usage: "directory for exported data files"},
+	"export-format":  {env: "EXPORT_FORMATS", usage: "comma-separated export formats: json, csv, ndjson"},
+}
+
+// activityFlags are accepted by every command that builds a work log
+var activityFlags = []string{"config", "config-profile", "username", "identities", "repos", "exclude-repos", "period", "since", "until", "timezone", "store-dir", "no-cache", "work-log"}
+
+// command is a git-log subcommand
+type command struct {
+	name    string
+	summary string
+	// usage lists the positional arguments, if any
+	usage string
+	flags []string
+	// needsProvider commands require an LLM API key
+	needsProvider bool
+	// providerSubcommands require an LLM API key when only some uses of the command do
+	providerSubcommands []string
+	// setup registers the command's own flags and returns its action
+	setup func(fs *flag.FlagSet) func(config *config.Config, args []string) error
+}
+
+var commands = []command{
+	{
+		name:          "report",
+		summary:       "Fetch activity and write the reports (the default)",
+		flags:         append(activityFlags, "provider", "model", "report-path", "renderer", "profiles", "force", "yes", "summarize", "dry-run", "dry-run-dir", "export", "export-dir", "export-format"),
+		needsProvider: true,
+		setup:         func(*flag.FlagSet) func(*config.Config, []string) error { return runReport },
+	},
+	{
+		name:    "fetch",
+		summary: "Fetch activity from GitHub, syncing the store if one is configured",
+		flags:   activityFlags,
+		setup:   func(*flag.FlagSet) func(*config.Config, []string) error { return runFetch },
+	},
+	{
+		name:    "build",
+		summary: "Build the work log and write it as JSON",
+		flags:   activityFlags,
+		setup: func(fs *flag.FlagSet) func(*config.Config, []string) error {
+			out := fs.String("out", "work_log.json", "file the work log is written to")
+			return func(config *config.Config, args []string) error {
+				return runBuild(config, config.ResolvePath(*out))
+			}
+		},
+	},
+	{
+		name:    "export",
+		summary: "Write the work log as JSON, CSV and NDJSON data files",
+		flags:   append(activityFlags, "export-dir", "export-format"),
+		setup:   func(*flag.FlagSet) func(*config.Config, []string) error { return runExport },
+	},
+	{
+		name:    "show",
+		summary: "Print a summary of the activity by repository",
+		flags:   activityFlags,
+		setup:   func(*flag.FlagSet) func(*config.Config, []string) error { return runShow },
+	},
+	{
+		name:                "config",
+		summary:             "Check the configuration and print the resolved settings; check also tries the GitHub token and LLM key",
+		usage:               "validate|check",
+		flags:               append(activityFlags, "provider", "model", "report-path", "renderer", "profiles"),
+		providerSubcommands: []string{"check"},
+		setup:               func(*flag.FlagSet) func(*config.Config, []string) error { return runConfig },
+	},
+}
+
 func main() {
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+
+		var cmdErr *commandError
+		if errors.As(err, &cmdErr) {
+			os.Exit(cmdErr.code)
+		}
+		os.Exit(exitError)
 	}
 }
 
-func run() error {
+func run(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			printUsage()
+			return nil
+		}
+	}
+
+	// Without a subcommand the reports are written, as before subcommands existed
+	name := "report"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	cmd, ok := lookupCommand(name)
+	if !ok {
+		printUsage()
+		return configError(fmt.Errorf("unknown command %q", name))
+	}
+
+	fs := flag.NewFlagSet("git-log "+cmd.name, flag.ContinueOnError)
+	for _, flagName := range cmd.flags {
+		setting := settingFlags[flagName]
+		if setting.boolean {
+			fs.Bool(flagName, false, fmt.Sprintf("%s (overrides %s)", setting.usage, setting.env))
+		} else {
+			fs.String(flagName, "", fmt.Sprintf("%s (overrides %s)", setting.usage, setting.env))
+		}
+	}
+	action := cmd.setup(fs)
+	fs.Usage = func() { printCommandUsage(cmd, fs) }
+
+	// Flags may come before or after positional arguments, as in "config validate --model=..."
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return configError(err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	// Flags take precedence over the environment
+	fs.Visit(func(f *flag.Flag) {
+		if setting, ok := settingFlags[f.Name]; ok {
+			os.Setenv(setting.env, f.Value.String())
+		}
+	})
+
+	load := config.LoadWithoutProvider
+	if cmd.needsProvider || (len(positional) > 0 && slices.Contains(cmd.providerSubcommands, positional[0])) {
+		load = config.Load
+	}
+	config, err := load()
+	if err != nil {
+		return configError(fmt.Errorf("loading config: %w", err))
+	}
+
+	return action(config, positional)
+}
+
+func lookupCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: git-log [command] [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nEvery flag overrides the environment variable named in its help.")
+	fmt.Fprintln(os.Stderr, "Run 'git-log <command> --help' for the flags of a command.")
+	fmt.Fprintf(os.Stderr, "\nExit codes: %d error, %d configuration error, %d GitHub API error, %d LLM error\n",
+		exitError, exitConfigError, exitAPIError, exitLLMError)
+}
+
+func printCommandUsage(cmd command, fs *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: git-log %s [flags] %s\n\n%s\n\nFlags:\n", cmd.name, cmd.usage, cmd.summary)
+	fs.PrintDefaults()
+}
+
+// resolveProfiles looks up the configured profiles, so a typo fails before anything is fetched
+func resolveProfiles(config *config.Config) ([]report.Profile, error) {
+	reportProfiles := make([]report.Profile, 0, len(config.Profiles))
+	for _, name := range config.Profiles {
+		profile, err := report.LookupProfile(name)
+		if err != nil {
+			return nil, configError(fmt.Errorf("loading config: %w", err))
+		}
+		reportProfiles = append(reportProfiles, profile)
+	}
+	return reportProfiles, nil
+}
+
+// buildWorkLog resolves the profiles and builds the work log of the configured window
+func buildWorkLog(config *config.Config) (*processing.WorkLog, time.Time, []report.Profile, error) {
+	reportProfiles, err := resolveProfiles(config)
+	if err != nil {
+		return nil, time.Time{}, nil, err
+	}
+
+	// Create a context with timeout for the entire API requests
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	workLog, _, until, err := loadWorkLog(ctx, config, reportProfiles)
+	if err != nil {
+		return nil, until, nil, err
+	}
+	printSummary(config, workLog)
+	return workLog, until, reportProfiles, nil
+}
 
-	config, err := config.Load()
+func runReport(config *config.Config, args []string) error {
+	reportProfiles, err := resolveProfiles(config)
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
-		return fmt.Errorf("loading config: %w", err)
+		return err
 	}
 
 	// Create a context with timeout for the entire API requests
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	// Convert int days to time.Time
-	since := time.Now().AddDate(0, 0, -config.LookbackDays)
+	workLog, client, until, err := loadWorkLog(ctx, config, reportProfiles)
+	if err != nil {
+		return err
+	}
+	printSummary(config, workLog)
+
+	if config.ExportDir != "" {
+		if err := writeExport(config, workLog); err != nil {
+			return err
+		}
+	}
+	if config.ExportOnly {
+		return nil
+	}
+
+	if config.DryRun {
+		return dryRun(config, reportProfiles, *workLog)
+	}
 
-	fmt.Println("Fetching GitHub activity...")
+	// Summaries stand in for PR descriptions in every profile, so they are made once per run
+	if config.Summarize && config.Renderer != "template" {
+		summarized, err := summarize(ctx, config, client, *workLog)
+		if err != nil {
+			return llmError(fmt.Errorf("summarizing pull requests: %w", err))
+		}
+		workLog = &summarized
+	}
 
-	var client = github.NewClient(config.GitHubToken)
+	for _, profile := range reportProfiles {
+		if err := writeProfile(config, profile, *workLog, until); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-	commits, err := client.GetCommits(ctx, config.Username, since)
+func runFetch(config *config.Config, args []string) error {
+	_, _, _, err := buildWorkLog(config)
+	return err
+}
+
+func runBuild(config *config.Config, out string) error {
+	workLog, _, _, err := buildWorkLog(config)
 	if err != nil {
-		fmt.Printf("Warning: Failed to fetch commits: %v\n", err)
-		fmt.Println("Continuing with pull requests only...")
-		commits = []github.CommitSearchResultItem{}
+		return err
 	}
 
-	pullRequests, err := client.GetPullRequests(ctx, config.Username, since)
+	logBytes, err := json.MarshalIndent(workLog, "", "  ")
 	if err != nil {
-		fmt.Printf("Error getting pull requests: %v\n", err)
-		return fmt.Errorf("fetching pull requests: %w", err)
+		return fmt.Errorf("encoding work log: %w", err)
 	}
+	if err := os.WriteFile(out, append(logBytes, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing work log: %w", err)
+	}
+	fmt.Printf("Work log saved to %s\n", out)
+	return nil
+}
 
-	fmt.Printf("Found %d pull requests and %d commits\n", len(pullRequests), len(commits))
+func runExport(config *config.Config, args []string) error {
+	workLog, _, _, err := buildWorkLog(config)
+	if err != nil {
+		return err
+	}
 
-	// Process and group data
-	fmt.Println("Processing activity data...")
-	workLog := processing.GroupByRepository(pullRequests, commits)
+	if config.ExportDir == "" {
+		config.ExportDir = config.ResolvePath("export")
+	}
+	return writeExport(config, workLog)
+}
+
+func runShow(config *config.Config, args []string) error {
+	workLog, _, _, err := buildWorkLog(config)
+	if err != nil {
+		return err
+	}
 
-	// Display summary
+	fmt.Printf("\n%-40s %6s %8s %8s\n", "Repository", "PRs", "Commits", "Reviews")
+	for _, repo := range workLog.Repositories {
+		fmt.Printf("%-40s %6d %8d %8d\n", repo.FullName, len(repo.PullRequests), len(repo.Commits), len(repo.Reviews))
+	}
+	return nil
+}
+
+func runConfig(config *config.Config, args []string) error {
+	if len(args) != 1 || (args[0] != "validate" && args[0] != "check") {
+		return configError(fmt.Errorf("expected 'git-log config validate' or 'git-log config check'"))
+	}
+
+	if _, err := resolveProfiles(config); err != nil {
+		return err
+	}
+
+	settings := map[string]string{
+		"Username":   config.Username,
+		"Config":     valueOr(config.ConfigFile, "none"),
+		"Identities": valueOr(strings.Join(config.Identities, ", "), "none"),
+		"Repos":      valueOr(strings.Join(config.Repos, ", "), "all"),
+		"Excluded":   valueOr(strings.Join(config.ExcludeRepos, ", "), "none"),
+		"Window":     fmt.Sprintf("%s to %s (%s)", config.Since.Format("Jan 2, 2006"), config.Until.Format("Jan 2, 2006"), config.Location),
+		"Renderer":   config.Renderer,
+		"Provider":   config.Provider,
+		"Model":      config.Model,
+		"Report":     config.ReportPath,
+		"Profiles":   strings.Join(config.Profiles, ", "),
+		"Store":      valueOr(config.StoreDir, "disabled"),
+		"Cache":      config.CacheDir,
+		"Work log":   valueOr(config.WorkLogPath, "fetched from GitHub"),
+		"Merge":      config.MergeMode,
+		"Summaries":  fmt.Sprintf("%t", config.Summarize),
+	}
+	if config.ConfigProfile != "" {
+		settings["Config"] += fmt.Sprintf(" (profile %s)", config.ConfigProfile)
+	}
+	if config.AutoSince {
+		settings["Window"] = "continues from the last report update"
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%-11s %s\n", name+":", settings[name])
+	}
+
+	fmt.Println("Configuration is valid")
+
+	if args[0] == "check" {
+		return checkCredentials(config)
+	}
+	return nil
+}
+
+// printSummary displays the totals of the work log
+func printSummary(config *config.Config, workLog *processing.WorkLog) {
 	fmt.Printf("\n=== Summary ===\n")
 	fmt.Printf("Repositories: %d\n", workLog.Summary.TotalRepositories)
 	fmt.Printf("Pull Requests: %d\n", workLog.Summary.TotalPullRequests)
 	fmt.Printf("Commits: %d\n", workLog.Summary.TotalCommits)
-	fmt.Printf("Period: %s to %s\n",
-		workLog.Summary.DateRange.Start.Format("Jan 2, 2006"),
-		workLog.Summary.DateRange.End.Format("Jan 2, 2006"))
+	fmt.Printf("Reviews: %d\n", workLog.Summary.TotalReviews)
+	fmt.Printf("Period: %s to %s (%s)\n",
+		workLog.Summary.DateRange.Start.In(config.Location).Format("Jan 2, 2006"),
+		workLog.Summary.DateRange.End.In(config.Location).Format("Jan 2, 2006"),
+		config.Location)
+}
 
-	// Analyse and generate report
-	fmt.Println("Generating accomplishment report...")
-	result, err := report.GenerateReport(config.Model, *workLog, config.ReportPath)
+// writeExport writes the work log data files to the export directory
+func writeExport(config *config.Config, workLog *processing.WorkLog) error {
+	files, err := export.Write(config.ExportDir, *workLog, config.ExportFormats)
 	if err != nil {
-		return fmt.Errorf("generating report: %w", err)
+		return fmt.Errorf("exporting work log: %w", err)
 	}
-
-	// Save report to file
-	err = os.WriteFile(config.ReportPath, []byte(result), 0644)
-	if err != nil {
-		return fmt.Errorf("writing report to file: %w", err)
+	for _, file := range files {
+		fmt.Printf("Exported %s\n", file)
 	}
-	fmt.Printf("Report saved to %s\n", config.ReportPath)
-
 	return nil
 }
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"git-log/config"
+	"git-log/internal/github"
+	"git-log/internal/processing"
+	"git-log/internal/report"
+)
+
+// writeProfile renders or generates a single report profile and saves it
+func writeProfile(config *config.Config, profile report.Profile, workLog processing.WorkLog, until time.Time) error {
+	reportPath := profileOutputPath(config.ReportPath, profile)
+	workLog = profile.WorkLog(workLog)
+
+	var result string
+	var err error
+	if config.Renderer == "template" {
+		fmt.Printf("Rendering %s report from template...\n", profile.Name)
+		result, err = report.RenderTemplate(workLog, profile, config.TemplatePath)
+		if err != nil {
+			return fmt.Errorf("rendering report: %w", err)
+		}
+		// The template only sees the current window, so add it to the report's history
+		if profile.Merge {
+			previous, err := os.ReadFile(reportPath)
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("reading previous report: %w", err)
+			}
+			if len(previous) > 0 {
+				result = report.AppendRendered(report.StripMarker(string(previous)), result, workLog)
+			}
+		}
+	} else {
+		fmt.Printf("Generating %s report...\n", profile.Name)
+		result, err = generate(config, profile, workLog, reportPath)
+		if err != nil {
+			return llmError(fmt.Errorf("generating report: %w", err))
+		}
+	}
+
+	// Merged reports accumulate history, so refuse to silently lose it
+	if profile.Merge && !config.Force {
+		if err := guardContentLoss(config, reportPath, result); err != nil {
+			return err
+		}
+	}
+
+	result, accepted, err := reviewReport(config, reportPath, result)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		fmt.Printf("Left %s unchanged\n", reportPath)
+		return nil
+	}
+
+	// Record the covered window so the next run picks up where this one ended
+	result = report.StampMarker(result, until)
+
+	// Save report to file
+	err = os.WriteFile(reportPath, []byte(result), 0644)
+	if err != nil {
+		return fmt.Errorf("writing report to file: %w", err)
+	}
+	fmt.Printf("Report saved to %s\n", reportPath)
+
+	return nil
+}
+
+// reviewReport prints a diff of the report against the one on disk and, in a terminal, asks
+// whether to write it. It returns the report to write, which may have been edited, and false
+// when the changes were rejected. CI runs and AUTO_ACCEPT write without asking.
+func reviewReport(config *config.Config, reportPath, result string) (string, bool, error) {
+	previous, err := os.ReadFile(reportPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", false, fmt.Errorf("reading previous report: %w", err)
+	}
+
+	interactive := !config.AutoAccept && isTerminal(os.Stdin)
+	input := bufio.NewReader(os.Stdin)
+	showDiff := true
+
+	for {
+		if showDiff {
+			diff := report.UnifiedDiff(filepath.Base(reportPath),
+				normalizeReport(report.StripMarker(string(previous))), normalizeReport(result))
+			if diff == "" {
+				fmt.Printf("No changes to %s\n", reportPath)
+				return result, true, nil
+			}
+
+			added, removed := report.DiffSummary(diff)
+			fmt.Printf("\n=== Changes to %s (+%d, -%d lines) ===\n%s\n", reportPath, added, removed, diff)
+			showDiff = false
+		}
+
+		if !interactive {
+			return result, true, nil
+		}
+
+		fmt.Print("Write these changes? [y]es, [n]o, [e]dit: ")
+		answer, err := input.ReadString('\n')
+		if err != nil {
+			return "", false, fmt.Errorf("reading answer: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return result, true, nil
+		case "n", "no":
+			return "", false, nil
+		case "e", "edit":
+			result, err = editReport(result)
+			if err != nil {
+				return "", false, err
+			}
+			showDiff = true
+		default:
+			fmt.Println("Please answer y, n or e")
+		}
+	}
+}
+
+// editReport opens the report in the user's editor and returns the edited text
+func editReport(content string) (string, error) {
+	file, err := os.CreateTemp("", "git-log-*.md")
+	if err != nil {
+		return "", fmt.Errorf("creating temporary report: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return "", fmt.Errorf("writing temporary report: %w", err)
+	}
+	file.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor may come with arguments, e.g. "code --wait"
+	args := append(strings.Fields(editor), file.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running editor %q: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("reading edited report: %w", err)
+	}
+	return string(edited), nil
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// normalizeReport ends the report with exactly one newline so trailing whitespace doesn't show up as a change
+func normalizeReport(content string) string {
+	return strings.TrimRight(content, "\n") + "\n"
+}
+
+// guardContentLoss compares the new report with the one on disk and rejects it if too much was
+// lost. The run fails either way; in side-file mode the rejected report is kept for inspection.
+func guardContentLoss(config *config.Config, reportPath, result string) error {
+	previous, err := os.ReadFile(reportPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading previous report: %w", err)
+	}
+
+	loss := report.CompareReports(report.StripMarker(string(previous)), result)
+	if !loss.Exceeds(report.GuardLimits{MaxShrink: config.GuardMaxShrink, MaxRemoved: config.GuardMaxRemoved}) {
+		return nil
+	}
+
+	fmt.Printf("Warning: The new report lost content compared to %s:\n%s\n", reportPath, loss)
+
+	if config.GuardMode == "side-file" {
+		sidePath := strings.TrimSuffix(reportPath, filepath.Ext(reportPath)) + ".rejected" + filepath.Ext(reportPath)
+		if err := os.WriteFile(sidePath, []byte(result), 0644); err != nil {
+			return fmt.Errorf("writing rejected report: %w", err)
+		}
+		fmt.Printf("Rejected report saved to %s\n", sidePath)
+	}
+
+	return fmt.Errorf("refusing to overwrite %s because the new report lost content (use --force to override)", reportPath)
+}
+
+// profileOutputPath resolves a profile's output path next to the main report
+func profileOutputPath(reportPath string, profile report.Profile) string {
+	if profile.OutputPath == "" {
+		return reportPath
+	}
+	return filepath.Join(filepath.Dir(reportPath), profile.OutputPath)
+}
+
+// generate analyses the work log with the configured provider and merges it into the existing report
+func generate(config *config.Config, profile report.Profile, workLog processing.WorkLog, reportPath string) (string, error) {
+	req, err := reportRequest(config, profile, workLog, reportPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Generation can take longer than the API timeout, so it gets its own context
+	ctx := context.Background()
+	provider, err := newProvider(ctx, config)
+	if err != nil {
+		return "", err
+	}
+
+	return report.GenerateReport(ctx, provider, req)
+}
+
+// reportRequest describes the generation of a profile's report
+func reportRequest(config *config.Config, profile report.Profile, workLog processing.WorkLog, reportPath string) (report.Request, error) {
+	// A custom system prompt replaces the default profile's; other profiles keep their own
+	systemPromptPath := ""
+	if profile.Name == report.DefaultProfile {
+		systemPromptPath = config.SystemPrompt
+	}
+
+	prompts, err := report.LoadPrompts(systemPromptPath, config.UserPrompt)
+	if err != nil {
+		return report.Request{}, fmt.Errorf("loading prompts: %w", err)
+	}
+	if systemPromptPath == "" {
+		prompts.System = profile.SystemPrompt
+	}
+
+	audience := profile.Audience
+	if config.Audience != "" {
+		audience = config.Audience
+	}
+
+	return report.Request{
+		WorkLog:    workLog,
+		ReportPath: reportPath,
+		Username:   config.Username,
+		Audience:   audience,
+		Prompts:    prompts,
+		Regenerate: !profile.Merge,
+		// The structured schema and the section layout describe the accomplishment log only
+		SectionMerge:     config.MergeMode == "sections" && profile.Name == report.DefaultProfile,
+		Structured:       config.Structured && profile.Name == report.DefaultProfile,
+		CheckIntegrity:   profile.References && config.IntegrityRetries >= 0,
+		IntegrityRetries: config.IntegrityRetries,
+		TokenBudget:      config.TokenBudget,
+		BodyLimit:        config.BodyLimit,
+		Options: report.GenerateOptions{
+			Temperature: config.Temperature,
+			MaxTokens:   config.MaxTokens,
+		},
+	}, nil
+}
+
+// dryRun writes the work log and every prompt that would be sent, with token and cost
+// estimates, without calling the provider or touching any report
+func dryRun(config *config.Config, profiles []report.Profile, workLog processing.WorkLog) error {
+	// Token estimates only need the provider's type, so a missing API key is not fatal here
+	provider, err := newProvider(context.Background(), config)
+	if err != nil {
+		fmt.Printf("Warning: %v, estimating tokens generically\n", err)
+		provider = nil
+	}
+
+	// Summaries aren't generated without calling the provider, but the ones already made are used
+	unsummarized := 0
+	summarizing := config.Summarize && config.Renderer != "template"
+	if summarizing {
+		workLog, unsummarized = report.ApplyCachedSummaries(workLog, report.NewSummaryStore(config.SummaryDir))
+	}
+
+	logBytes, err := json.MarshalIndent(workLog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding work log: %w", err)
+	}
+	if err := writeDryRunFile(config.DryRunDir, "work_log.json", string(logBytes)); err != nil {
+		return err
+	}
+
+	price, priced := report.LookupPrice(config.Model)
+	if config.Provider == report.ProviderOllama {
+		price, priced = report.ModelPrice{}, true
+	}
+
+	totalInput, totalOutput := 0, 0
+	var estimates []string
+	for _, profile := range profiles {
+		if config.Renderer == "template" {
+			estimates = append(estimates, fmt.Sprintf("%s: rendered from a template, no prompt", profile.Name))
+			continue
+		}
+
+		req, err := reportRequest(config, profile, profile.WorkLog(workLog), profileOutputPath(config.ReportPath, profile))
+		if err != nil {
+			return err
+		}
+		prompts, err := report.PreparePrompts(provider, req)
+		if err != nil {
+			return fmt.Errorf("preparing %s prompts: %w", profile.Name, err)
+		}
+
+		for i, prompt := range prompts {
+			name := profile.Name
+			if len(prompts) > 1 {
+				name = fmt.Sprintf("%s-batch-%d", profile.Name, i+1)
+			}
+			if err := writeDryRunFile(config.DryRunDir, name+".system.md", prompt.System); err != nil {
+				return err
+			}
+			if err := writeDryRunFile(config.DryRunDir, name+".user.md", prompt.User); err != nil {
+				return err
+			}
+
+			input, output := prompt.InputTokens(provider), prompt.OutputTokens(provider)
+			totalInput += input
+			totalOutput += output
+			estimates = append(estimates, fmt.Sprintf("%s: ~%d input tokens, ~%d output tokens", name, input, output))
+		}
+	}
+
+	fmt.Printf("\n=== Dry Run Estimate (%s) ===\n", config.Model)
+	for _, estimate := range estimates {
+		fmt.Println(estimate)
+	}
+	fmt.Printf("Total: ~%d input tokens, ~%d output tokens\n", totalInput, totalOutput)
+	if priced {
+		fmt.Printf("Estimated cost: $%.4f\n", price.Cost(totalInput, totalOutput))
+	} else {
+		fmt.Printf("Estimated cost: unknown, no list price for %s\n", config.Model)
+	}
+	if summarizing && unsummarized > 0 {
+		fmt.Printf("Summarization skipped: %d pull requests have no cached summary, so their full descriptions are counted and the cost of summarizing them is not included\n", unsummarized)
+	}
+	fmt.Println("Dry run: no provider was called and no report was written")
+
+	return nil
+}
+
+// writeDryRunFile writes one dry run artifact to dir, or prints it when dir is empty
+func writeDryRunFile(dir, name, content string) error {
+	if dir == "" {
+		fmt.Printf("\n=== %s ===\n%s\n", name, content)
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating dry run directory: %w", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	fmt.Printf("Wrote %s\n", path)
+	return nil
+}
+
+// summarize fetches the diffstat and commits of each pull request and replaces its description
+// with a short impact statement. Summaries are kept in the summary directory for later runs.
+func summarize(ctx context.Context, config *config.Config, client *github.Client, workLog processing.WorkLog) (processing.WorkLog, error) {
+	store := report.NewSummaryStore(config.SummaryDir)
+
+	// A replayed work log is summarized from what was saved. Details are only fetched
+	// for PRs that need a new summary, so a rerun with everything cached costs no requests.
+	if client != nil {
+		fmt.Println("Fetching pull request details...")
+		if err := processing.EnrichPullRequests(ctx, client, &workLog, func(repository string, pr processing.PullRequest) bool {
+			return !store.Has(repository, pr)
+		}); err != nil {
+			fmt.Printf("Warning: Failed to fetch some pull request details: %v\n", err)
+		}
+	}
+
+	provider, err := newProvider(context.Background(), config)
+	if err != nil {
+		return workLog, err
+	}
+
+	fmt.Printf("Summarizing pull requests into %s...\n", config.SummaryDir)
+	return report.SummarizePullRequests(context.Background(), provider, workLog, store, report.GenerateOptions{
+		Temperature: config.Temperature,
+	})
+}
+
+// newProvider creates the configured LLM provider
+func newProvider(ctx context.Context, config *config.Config) (report.Provider, error) {
+	provider, err := report.NewProvider(ctx, report.ProviderConfig{
+		Name:          config.Provider,
+		Model:         config.Model,
+		APIKey:        config.APIKey,
+		BaseURL:       config.BaseURL,
+		Organization:  config.Organization,
+		ContextWindow: config.ContextSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating %s provider: %w", config.Provider, err)
+	}
+	return provider, nil
+}
@@ -3,66 +3,484 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
-	"strconv"
+	"strings"
+	"time"
 )
 
+// DefaultLookbackDays is used when no window is configured and the report has no history.
+const DefaultLookbackDays = 30
+
+// defaultModels is the model used for each provider when MODEL is not set
+var defaultModels = map[string]string{
+	"gemini":    "gemini-2.5-flash",
+	"openai":    "gpt-4o-mini",
+	"anthropic": "claude-sonnet-4-5",
+	"ollama":    "llama3.1",
+}
+
 type Config struct {
-	GoogleToken  string
-	GitHubToken  string
-	Username     string
-	LookbackDays int
-	ReportPath   string
-	Model        string
+	GitHubToken      string
+	Username         string
+	Identities       []string
+	Repos            []string
+	ExcludeRepos     []string
+	LookbackDays     int
+	Period           string
+	Since            time.Time
+	Until            time.Time
+	AutoSince        bool
+	OpenEnded        bool
+	Location         *time.Location
+	ReportPath       string
+	Renderer         string
+	TemplatePath     string
+	SystemPrompt     string
+	UserPrompt       string
+	Audience         string
+	Profiles         []string
+	Structured       bool
+	MergeMode        string
+	IntegrityRetries int
+	GuardMaxShrink   float64
+	GuardMaxRemoved  int
+	GuardMode        string
+	Force            bool
+	StoreDir         string
+	CacheDir         string
+	CacheTTL         time.Duration
+	NoCache          bool
+	Provider         string
+	APIKey           string
+	BaseURL          string
+	Organization     string
+	ContextSize      int
+	Model            string
+	Temperature      *float32
+	MaxTokens        int
+	TokenBudget      int
+	BodyLimit        int
+	Summarize        bool
+	SummaryDir       string
+	DryRun           bool
+	DryRunDir        string
+	AutoAccept       bool
+	ExportDir        string
+	ExportFormats    []string
+	ExportOnly       bool
+	WorkLogPath      string
+	ConfigFile       string
+	ConfigProfile    string
 }
 
+// Load reads the configuration for writing reports: an LLM API key is required when a model
+// writes them, and the report path must be writable. Every problem is reported at once as Errors.
 func Load() (*Config, error) {
+	return load(true)
+}
+
+// LoadWithoutProvider reads the configuration for commands that never call the LLM or write reports
+func LoadWithoutProvider() (*Config, error) {
+	return load(false)
+}
+
+func load(writesReports bool) (*Config, error) {
+	s := &settings{}
+
+	// Settings missing from the environment are read from the config file, if there is one
+	file, err := LoadFile()
+	if err != nil {
+		s.errs = append(s.errs, err)
+	}
+	s.file = file
+	var configFile, configProfile string
+	if file != nil {
+		configFile, configProfile = file.Path, file.Profile
+	}
+
+	// Replaying a saved work log doesn't touch GitHub
+	workLogPath := s.existingFile("WORK_LOG_PATH")
 
-	githubToken := os.Getenv("ACCESS_TOKEN")
-	if githubToken == "" {
-		return nil, fmt.Errorf("ACCESS_TOKEN environment variable not set")
+	githubToken := s.string("ACCESS_TOKEN")
+	if workLogPath == "" && s.string("WORK_LOG_PATH") == "" {
+		githubToken = s.required("ACCESS_TOKEN")
 	}
 
-	username := os.Getenv("USERNAME")
-	if username == "" {
-		return nil, fmt.Errorf("USERNAME environment variable not set")
+	username := s.required("USERNAME")
+
+	// Other GitHub accounts of the same person, such as a separate work account
+	var identities []string
+	for _, identity := range splitList(s.string("IDENTITIES")) {
+		if !strings.EqualFold(identity, username) {
+			identities = append(identities, identity)
+		}
 	}
 
-	googleToken := os.Getenv("GOOGLE_API_KEY")
-	if googleToken == "" {
-		return nil, fmt.Errorf("GOOGLE_API_KEY environment variable not set")
+	// Repository filters are owner/name patterns, such as acme/* for a whole organization
+	repos := splitList(s.string("REPOS"))
+	excludeRepos := splitList(s.string("EXCLUDE_REPOS"))
+	for _, pattern := range append(append([]string{}, repos...), excludeRepos...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			s.errorf("invalid repository pattern %q: %v", pattern, err)
+		}
 	}
 
-	reportPath := os.Getenv("REPORT_PATH")
-	if reportPath == "" {
-		reportPath = "report.md"
+	renderer := s.oneOf("RENDERER", "llm", "llm", "template")
+	dryRun := s.boolean("DRY_RUN")
+
+	// An empty directory prints the dry run output instead of writing it to files
+	dryRunDir := s.path("DRY_RUN_DIR")
+
+	autoAccept := s.boolean("AUTO_ACCEPT")
+	exportOnly := s.boolean("EXPORT_ONLY")
+	if dryRun && exportOnly {
+		s.errorf("DRY_RUN and EXPORT_ONLY cannot be combined: an export stops before any prompt is built")
 	}
 
-	// Make report path absolute if it's relative and we're in GitHub Actions
-	if !filepath.IsAbs(reportPath) {
-		workspace := os.Getenv("GITHUB_WORKSPACE")
-		if workspace != "" {
-			reportPath = filepath.Join(workspace, reportPath)
+	// Data files are written on every run when a directory is set
+	exportDir := s.path("EXPORT_DIR")
+	if exportDir == "" && exportOnly {
+		exportDir = workspacePath("export")
+	}
+
+	exportFormats := []string{"json", "csv", "ndjson"}
+	if value := s.string("EXPORT_FORMATS"); value != "" {
+		exportFormats = nil
+		for _, format := range splitList(value) {
+			switch format {
+			case "json", "csv", "ndjson":
+				exportFormats = append(exportFormats, format)
+			default:
+				s.errorf("unknown EXPORT_FORMATS entry %q, expected json, csv or ndjson", format)
+			}
 		}
 	}
 
-	model := os.Getenv("MODEL")
+	// The template renderer, dry runs and exports never call a provider, so they need no API key
+	needsKey := writesReports && renderer == "llm" && !dryRun && !exportOnly
+
+	templatePath := s.existingFile("TEMPLATE_PATH")
+
+	// Prompt templates fall back to the embedded defaults when unset
+	systemPromptPath := s.existingFile("SYSTEM_PROMPT_PATH")
+	userPromptPath := s.existingFile("USER_PROMPT_PATH")
+
+	audience := s.string("AUDIENCE")
+	structured := s.boolean("STRUCTURED_OUTPUT")
+
+	// sections sends only the parts of the report the work log touches, document sends all of it
+	mergeMode := s.oneOf("MERGE_MODE", "sections", "sections", "document")
+
+	// -1 disables the integrity check, 0 only warns, N re-prompts up to N times
+	integrity := s.integer("INTEGRITY_RETRIES", 0, -1)
+
+	// The guard rejects merged reports that lose more than this much of the previous one
+	guardMaxShrink := s.float("GUARD_MAX_SHRINK", 0.2, 0, 1)
+	guardMaxRemoved := s.integer("GUARD_MAX_REMOVED", 0, 0)
+	guardMode := s.oneOf("GUARD_MODE", "side-file", "side-file", "refuse")
+	force := s.boolean("FORCE_WRITE")
+
+	profiles := splitList(s.string("PROFILES"))
+	if len(profiles) == 0 {
+		profiles = []string{"log"}
+	}
+
+	model := s.string("MODEL")
+
+	// Without an explicit provider, infer it from the model name
+	provider := s.string("PROVIDER")
+	if provider == "" {
+		provider = providerForModel(model)
+	}
+
 	if model == "" {
-		model = "gemini-2.5-flash"
+		model = defaultModels[provider]
 	}
 
-	days := (os.Getenv("LOOKBACK_DAYS"))
-	daysInt, err := strconv.Atoi(days)
-	if err != nil {
-		return nil, fmt.Errorf("invalid DAYS value: %v", err)
+	var apiKey, baseURL, organization string
+	var contextSize int
+	switch provider {
+	case "gemini":
+		apiKey = s.string("GOOGLE_API_KEY")
+		if needsKey {
+			apiKey = s.required("GOOGLE_API_KEY")
+		}
+	case "openai":
+		// Self-hosted gateways often don't need a key
+		apiKey = s.string("OPENAI_API_KEY")
+		baseURL = s.string("OPENAI_BASE_URL")
+		organization = s.string("OPENAI_ORG")
+	case "anthropic":
+		apiKey = s.string("ANTHROPIC_API_KEY")
+		if needsKey {
+			apiKey = s.required("ANTHROPIC_API_KEY")
+		}
+		baseURL = s.string("ANTHROPIC_BASE_URL")
+	case "ollama":
+		baseURL = s.string("OLLAMA_URL")
+		contextSize = s.integer("OLLAMA_NUM_CTX", 0, 0)
+	default:
+		s.errorf("unknown PROVIDER %q, expected gemini, openai, anthropic or ollama", provider)
+	}
+	if err := checkModel(provider, model, baseURL); err != nil {
+		s.errs = append(s.errs, err)
+	}
+
+	reportPath := s.path("REPORT_PATH")
+	if reportPath == "" {
+		reportPath = workspacePath("report.md")
+	}
+	if writesReports && !dryRun && !exportOnly {
+		if err := checkWritable(reportPath); err != nil {
+			s.errorf("REPORT_PATH cannot be written: %v", err)
+		}
+	}
+
+	// An empty store directory disables the persistent store
+	storeDir := s.path("STORE_DIR")
+
+	cacheDir := s.string("CACHE_DIR")
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			userCacheDir = os.TempDir()
+		}
+		cacheDir = filepath.Join(userCacheDir, "git-log")
+	}
+
+	summarize := s.boolean("SUMMARIZE")
+
+	// Options that only change how the model is prompted have nothing to do with the template renderer
+	if renderer == "template" {
+		for _, key := range []string{"STRUCTURED_OUTPUT", "SUMMARIZE"} {
+			if s.boolean(key) {
+				s.errorf("%s needs RENDERER=llm, the template renderer doesn't call a model", key)
+			}
+		}
+	} else if templatePath != "" {
+		s.errorf("TEMPLATE_PATH is only used with RENDERER=template")
+	}
+
+	// PR summaries live with the store when there is one, so they are kept across runs
+	summaryDir := s.path("SUMMARY_DIR")
+	switch {
+	case summaryDir != "":
+	case storeDir != "":
+		summaryDir = filepath.Join(storeDir, "summaries")
+	default:
+		summaryDir = filepath.Join(cacheDir, "summaries")
+	}
+
+	cacheTTL := s.duration("CACHE_TTL", 15*time.Minute)
+	noCache := s.boolean("NO_CACHE")
+
+	location := time.Local
+	if tz := s.string("TIMEZONE"); tz != "" {
+		var err error
+		location, err = time.LoadLocation(tz)
+		if err != nil {
+			s.errorf("invalid TIMEZONE value %q: expected an IANA timezone such as Europe/London", tz)
+			location = time.Local
+		}
+	}
+
+	var temperature *float32
+	if s.string("TEMPERATURE") != "" {
+		t := float32(s.float("TEMPERATURE", 0, 0, 2))
+		temperature = &t
+	}
+
+	maxTokens := s.integer("MAX_TOKENS", 0, 0)
+
+	// Zero leaves the prompt size to the provider's context window
+	tokenBudget := s.integer("TOKEN_BUDGET", 0, 0)
+	// Zero keeps PR descriptions whole, they are only shortened when the budget requires it
+	bodyLimit := s.integer("BODY_LIMIT", 0, 0)
+
+	// Window boundaries such as "last-month" are computed on the configured wall clock
+	now := time.Now().In(location)
+	period := s.string("PERIOD")
+	sinceValue := s.string("SINCE")
+	untilValue := s.string("UNTIL")
+
+	var daysInt int
+	var since, until time.Time
+	autoSince := false
+	windowValid := true
+
+	switch {
+	case period != "":
+		if sinceValue != "" || untilValue != "" {
+			s.errorf("PERIOD cannot be combined with SINCE or UNTIL")
+		}
+		var err error
+		since, until, err = ParsePeriod(period, now)
+		if err != nil {
+			s.errorf("invalid PERIOD value: %v", err)
+			windowValid = false
+		}
+	case sinceValue != "":
+		var err error
+		since, err = parseDate(sinceValue, false, now.Location())
+		if err != nil {
+			s.errorf("invalid SINCE value: %v", err)
+			windowValid = false
+		}
+		until = now
+		if untilValue != "" {
+			until, err = parseDate(untilValue, true, now.Location())
+			if err != nil {
+				s.errorf("invalid UNTIL value: %v", err)
+				windowValid = false
+			}
+		}
+	default:
+		if untilValue != "" {
+			s.errorf("UNTIL requires SINCE to be set")
+		}
+		until = now
+		if s.string("LOOKBACK_DAYS") == "" {
+			// Since is resolved later from the report's history
+			autoSince = true
+			break
+		}
+		daysInt = s.integer("LOOKBACK_DAYS", 0, 1)
+		if daysInt == 0 {
+			windowValid = false
+			break
+		}
+		// Start at midnight so repeated runs on the same day issue identical, cacheable queries
+		since = time.Date(now.Year(), now.Month(), now.Day()-daysInt, 0, 0, 0, 0, location)
+	}
+
+	if windowValid && !autoSince && !since.Before(until) {
+		s.errorf("reporting window is empty: %s is not before %s",
+			since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+
+	if len(s.errs) > 0 {
+		return nil, s.errs
 	}
 
 	return &Config{
-		GoogleToken:  googleToken,
-		GitHubToken:  githubToken,
-		Username:     username,
-		LookbackDays: daysInt,
-		ReportPath:   reportPath,
-		Model:        model,
+		GitHubToken:      githubToken,
+		Username:         username,
+		Identities:       identities,
+		Repos:            repos,
+		ExcludeRepos:     excludeRepos,
+		LookbackDays:     daysInt,
+		Period:           period,
+		Since:            since,
+		Until:            until,
+		AutoSince:        autoSince,
+		OpenEnded:        until.Equal(now),
+		Location:         location,
+		ReportPath:       reportPath,
+		Renderer:         renderer,
+		TemplatePath:     templatePath,
+		SystemPrompt:     systemPromptPath,
+		UserPrompt:       userPromptPath,
+		Audience:         audience,
+		Profiles:         profiles,
+		Structured:       structured,
+		MergeMode:        mergeMode,
+		IntegrityRetries: integrity,
+		GuardMaxShrink:   guardMaxShrink,
+		GuardMaxRemoved:  guardMaxRemoved,
+		GuardMode:        guardMode,
+		Force:            force,
+		StoreDir:         storeDir,
+		CacheDir:         cacheDir,
+		CacheTTL:         cacheTTL,
+		NoCache:          noCache,
+		Provider:         provider,
+		APIKey:           apiKey,
+		BaseURL:          baseURL,
+		Organization:     organization,
+		ContextSize:      contextSize,
+		Model:            model,
+		Temperature:      temperature,
+		MaxTokens:        maxTokens,
+		TokenBudget:      tokenBudget,
+		BodyLimit:        bodyLimit,
+		Summarize:        summarize,
+		SummaryDir:       summaryDir,
+		DryRun:           dryRun,
+		DryRunDir:        dryRunDir,
+		AutoAccept:       autoAccept,
+		ExportDir:        exportDir,
+		ExportFormats:    exportFormats,
+		ExportOnly:       exportOnly,
+		WorkLogPath:      workLogPath,
+		ConfigFile:       configFile,
+		ConfigProfile:    configProfile,
 	}, nil
 }
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ResolvePath resolves a path that doesn't come from a setting, such as a command's
+// flag or default, the same way setting paths are resolved
+func (c *Config) ResolvePath(path string) string {
+	return workspacePath(path)
+}
+
+// workspacePath makes a relative path absolute if we're in GitHub Actions
+func workspacePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	if workspace == "" {
+		return path
+	}
+	return filepath.Join(workspace, path)
+}
+
+// modelPrefixes are the name prefixes of each hosted provider's models
+var modelPrefixes = map[string][]string{
+	"gemini":    {"gemini-", "gemma-"},
+	"openai":    {"gpt-", "chatgpt-", "o1", "o3", "o4"},
+	"anthropic": {"claude-"},
+}
+
+// checkModel rejects a model the provider doesn't serve, such as a claude model with PROVIDER=openai.
+// Ollama and OpenAI-compatible gateways serve arbitrary models, so those are not checked.
+func checkModel(provider, model, baseURL string) error {
+	prefixes, ok := modelPrefixes[provider]
+	if !ok || (provider == "openai" && baseURL != "") {
+		return nil
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return nil
+		}
+	}
+	if other := providerForModel(model); other != provider && other != "gemini" {
+		return fmt.Errorf("MODEL %q is served by %s, but PROVIDER is %s", model, other, provider)
+	}
+	return fmt.Errorf("unknown %s model %q", provider, model)
+}
+
+// providerForModel guesses the provider from the model name prefixes, defaulting to gemini.
+// The prefixes of different providers don't overlap, so the map order doesn't matter.
+func providerForModel(model string) string {
+	for provider, prefixes := range modelPrefixes {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(model, prefix) {
+				return provider
+			}
+		}
+	}
+	return "gemini"
+}
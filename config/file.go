@@ -0,0 +1,285 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the config file looked for in the repository
+const ConfigFileName = ".git-log.yaml"
+
+// fileKeys maps the keys of a config file to the environment variables they stand in for.
+// Secrets such as ACCESS_TOKEN and API keys are only read from the environment.
+var fileKeys = map[string]string{
+	"username":      "USERNAME",
+	"identities":    "IDENTITIES",
+	"timezone":      "TIMEZONE",
+	"lookback_days": "LOOKBACK_DAYS",
+	"period":        "PERIOD",
+	"since":         "SINCE",
+	"until":         "UNTIL",
+
+	"sources.store_dir": "STORE_DIR",
+	"sources.cache_dir": "CACHE_DIR",
+	"sources.cache_ttl": "CACHE_TTL",
+	"sources.work_log":  "WORK_LOG_PATH",
+
+	"repos.include": "REPOS",
+	"repos.exclude": "EXCLUDE_REPOS",
+
+	"provider.name":               "PROVIDER",
+	"provider.model":              "MODEL",
+	"provider.temperature":        "TEMPERATURE",
+	"provider.max_tokens":         "MAX_TOKENS",
+	"provider.token_budget":       "TOKEN_BUDGET",
+	"provider.body_limit":         "BODY_LIMIT",
+	"provider.structured_output":  "STRUCTURED_OUTPUT",
+	"provider.integrity_retries":  "INTEGRITY_RETRIES",
+	"provider.summarize":          "SUMMARIZE",
+	"provider.summary_dir":        "SUMMARY_DIR",
+	"provider.openai_base_url":    "OPENAI_BASE_URL",
+	"provider.openai_org":         "OPENAI_ORG",
+	"provider.anthropic_base_url": "ANTHROPIC_BASE_URL",
+	"provider.ollama_url":         "OLLAMA_URL",
+	"provider.ollama_num_ctx":     "OLLAMA_NUM_CTX",
+
+	"prompts.system":   "SYSTEM_PROMPT_PATH",
+	"prompts.user":     "USER_PROMPT_PATH",
+	"prompts.audience": "AUDIENCE",
+	"prompts.template": "TEMPLATE_PATH",
+
+	"outputs.report_path":       "REPORT_PATH",
+	"outputs.renderer":          "RENDERER",
+	"outputs.profiles":          "PROFILES",
+	"outputs.merge_mode":        "MERGE_MODE",
+	"outputs.guard_max_shrink":  "GUARD_MAX_SHRINK",
+	"outputs.guard_max_removed": "GUARD_MAX_REMOVED",
+	"outputs.guard_mode":        "GUARD_MODE",
+	"outputs.export_dir":        "EXPORT_DIR",
+	"outputs.export_formats":    "EXPORT_FORMATS",
+	"outputs.dry_run_dir":       "DRY_RUN_DIR",
+}
+
+// File is a parsed config file
+type File struct {
+	Path string
+	// Profile is the named profile applied on top of the shared settings, if any
+	Profile string
+	// settings are the file's values keyed by environment variable
+	settings map[string]string
+}
+
+// Getenv returns the environment variable, falling back to the config file's value for it.
+// Flags are applied to the environment before the config is loaded, so they win over both.
+func (f *File) Getenv(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	if f == nil {
+		return ""
+	}
+	return f.settings[key]
+}
+
+// configFilePath finds the config file: CONFIG_FILE, then .git-log.yaml in the repository,
+// then git-log/config.yaml in the user's config directory. It returns "" when there is none.
+func configFilePath() (string, error) {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		path = workspacePath(path)
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("reading CONFIG_FILE: %w", err)
+		}
+		return path, nil
+	}
+
+	candidates := []string{workspacePath(ConfigFileName)}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configHome = filepath.Join(home, ".config")
+		}
+	}
+	if configHome != "" {
+		candidates = append(candidates, filepath.Join(configHome, "git-log", "config.yaml"))
+	}
+
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// LoadFile reads the config file, if there is one, and applies the profile named by
+// CONFIG_PROFILE or the file's default_profile on top of its shared settings
+func LoadFile() (*File, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	profile := os.Getenv("CONFIG_PROFILE")
+	if path == "" {
+		if profile != "" {
+			return nil, fmt.Errorf("CONFIG_PROFILE is %q but no config file was found", profile)
+		}
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	file, err := parseFile(path, string(data), profile)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+func parseFile(path, data, profile string) (*File, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &document); err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]string)
+	profiles := map[string]*yaml.Node{}
+	var profileNames []string
+
+	root, err := mappingEntries(document.Content...)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range root {
+		switch entry.key {
+		case "default_profile":
+			if entry.value.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: default_profile must be a profile name", entry.value.Line)
+			}
+			if profile == "" {
+				profile = entry.value.Value
+			}
+		case "profiles":
+			entries, err := mappingEntries(entry.value)
+			if err != nil {
+				return nil, fmt.Errorf("profiles must map profile names to settings: %w", err)
+			}
+			for _, named := range entries {
+				profiles[named.key] = named.value
+				profileNames = append(profileNames, named.key)
+			}
+		default:
+			if err := flattenSettings(settings, entry.key, entry.value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// A profile's settings override the shared ones
+	if profile != "" {
+		node, ok := profiles[profile]
+		if !ok {
+			sort.Strings(profileNames)
+			return nil, fmt.Errorf("unknown profile %q, the file defines: %s", profile, strings.Join(profileNames, ", "))
+		}
+		entries, err := mappingEntries(node)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q must map keys to settings: %w", profile, err)
+		}
+		for _, entry := range entries {
+			if err := flattenSettings(settings, entry.key, entry.value); err != nil {
+				return nil, fmt.Errorf("profile %q: %w", profile, err)
+			}
+		}
+	}
+
+	return &File{Path: path, Profile: profile, settings: settings}, nil
+}
+
+// mappingEntry is a key and its value in a YAML mapping
+type mappingEntry struct {
+	key   string
+	value *yaml.Node
+}
+
+// mappingEntries returns the entries of a mapping node in file order, following aliases.
+// No node, as in an empty file, is an empty mapping. Duplicate keys are an error.
+func mappingEntries(nodes ...*yaml.Node) ([]mappingEntry, error) {
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	node := resolveAlias(nodes[0])
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected \"key: value\" pairs", node.Line)
+	}
+
+	seen := make(map[string]bool)
+	var entries []mappingEntry
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if seen[key.Value] {
+			return nil, fmt.Errorf("line %d: duplicate key %q", key.Line, key.Value)
+		}
+		seen[key.Value] = true
+		entries = append(entries, mappingEntry{key: key.Value, value: resolveAlias(node.Content[i+1])})
+	}
+	return entries, nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+// flattenSettings stores a value under the environment variable its dotted key stands for.
+// Scalars keep the text they were written with, and lists become comma-separated values,
+// as the environment variables expect.
+func flattenSettings(settings map[string]string, key string, value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		entries, err := mappingEntries(value)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := flattenSettings(settings, key+"."+entry.key, entry.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	env, ok := fileKeys[key]
+	if !ok {
+		return fmt.Errorf("line %d: unknown setting %q", value.Line, key)
+	}
+
+	switch value.Kind {
+	case yaml.ScalarNode:
+		if value.Tag != "!!null" {
+			settings[env] = value.Value
+		}
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(value.Content))
+		for _, item := range value.Content {
+			item = resolveAlias(item)
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: %s must be a list of plain values", item.Line, key)
+			}
+			items = append(items, item.Value)
+		}
+		settings[env] = strings.Join(items, ",")
+	}
+	return nil
+}
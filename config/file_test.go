@@ -0,0 +1,134 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		profile string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "nested maps",
+			data: `
+username: jdoe
+provider:
+  name: anthropic
+  model: claude-sonnet-4-5
+outputs:
+  report_path: reports/log.md
+`,
+			want: map[string]string{
+				"USERNAME":    "jdoe",
+				"PROVIDER":    "anthropic",
+				"MODEL":       "claude-sonnet-4-5",
+				"REPORT_PATH": "reports/log.md",
+			},
+		},
+		{
+			name: "lists in block and flow style",
+			data: `
+identities:
+- jdoe
+- jdoe-corp
+repos:
+  include:
+    - acme/*
+    - "me/side-project"
+  exclude: [acme/sandbox, 'acme/old']
+`,
+			want: map[string]string{
+				"IDENTITIES":    "jdoe,jdoe-corp",
+				"REPOS":         "acme/*,me/side-project",
+				"EXCLUDE_REPOS": "acme/sandbox,acme/old",
+			},
+		},
+		{
+			name: "profile overrides a shared key",
+			data: `
+username: jdoe
+timezone: Europe/London
+default_profile: work
+profiles:
+  work:
+    username: jdoe-corp
+  oss:
+    username: jdoe-oss
+`,
+			want: map[string]string{"USERNAME": "jdoe-corp", "TIMEZONE": "Europe/London"},
+		},
+		{
+			name: "requested profile beats default_profile",
+			data: `
+default_profile: work
+profiles:
+  work:
+    username: jdoe-corp
+  oss:
+    username: jdoe-oss
+`,
+			profile: "oss",
+			want:    map[string]string{"USERNAME": "jdoe-oss"},
+		},
+		{
+			name:    "empty file",
+			data:    "# nothing here\n",
+			want:    map[string]string{},
+			profile: "",
+		},
+		{
+			name:    "duplicate keys",
+			data:    "username: a\nusername: b\n",
+			wantErr: `line 2: duplicate key "username"`,
+		},
+		{
+			name: "unknown profile",
+			data: `
+profiles:
+  work:
+    username: jdoe
+`,
+			profile: "oss",
+			wantErr: `unknown profile "oss", the file defines: work`,
+		},
+		{
+			name:    "unknown setting",
+			data:    "outputs:\n  reprt_path: x\n",
+			wantErr: `unknown setting "outputs.reprt_path"`,
+		},
+		{
+			name:    "nested list",
+			data:    "identities:\n  - [a, b]\n",
+			wantErr: "identities must be a list of plain values",
+		},
+		{
+			name:    "invalid YAML",
+			data:    "identities: [a, b\n",
+			wantErr: "yaml:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := parseFile("test.yaml", tt.data, tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(file.settings, tt.want) {
+				t.Errorf("parseFile() settings = %v, want %v", file.settings, tt.want)
+			}
+		})
+	}
+}
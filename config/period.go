@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	quarterPattern = regexp.MustCompile(`^(\d{4})-[Qq]([1-4])$`)
+	halfPattern    = regexp.MustCompile(`^[Hh]([12])-(\d{4})$`)
+	yearPattern    = regexp.MustCompile(`^(\d{4})$`)
+)
+
+// ParsePeriod resolves a named period into an inclusive time window.
+// Supported names: last-week, last-month, last-quarter, ytd, YYYY, YYYY-Qn and Hn-YYYY.
+func ParsePeriod(name string, now time.Time) (since, until time.Time, err error) {
+	loc := now.Location()
+	name = strings.TrimSpace(name)
+
+	switch strings.ToLower(name) {
+	case "last-week":
+		weekday := (int(now.Weekday()) + 6) % 7 // Monday = 0
+		thisWeek := time.Date(now.Year(), now.Month(), now.Day()-weekday, 0, 0, 0, 0, loc)
+		since = thisWeek.AddDate(0, 0, -7)
+		return since, endOf(thisWeek), nil
+	case "last-month":
+		thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+		since = thisMonth.AddDate(0, -1, 0)
+		return since, endOf(thisMonth), nil
+	case "last-quarter":
+		thisQuarter := time.Date(now.Year(), quarterStart(now.Month()), 1, 0, 0, 0, 0, loc)
+		since = thisQuarter.AddDate(0, -3, 0)
+		return since, endOf(thisQuarter), nil
+	case "ytd":
+		since = time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, loc)
+		return since, now, nil
+	}
+
+	if m := quarterPattern.FindStringSubmatch(name); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		quarter, _ := strconv.Atoi(m[2])
+		since = time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, loc)
+		return since, endOf(since.AddDate(0, 3, 0)), nil
+	}
+
+	if m := halfPattern.FindStringSubmatch(name); m != nil {
+		half, _ := strconv.Atoi(m[1])
+		year, _ := strconv.Atoi(m[2])
+		since = time.Date(year, time.Month((half-1)*6+1), 1, 0, 0, 0, 0, loc)
+		return since, endOf(since.AddDate(0, 6, 0)), nil
+	}
+
+	if m := yearPattern.FindStringSubmatch(name); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		since = time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+		return since, endOf(since.AddDate(1, 0, 0)), nil
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("unknown period %q", name)
+}
+
+// parseDate accepts either a plain date (YYYY-MM-DD) or an RFC3339 timestamp.
+// Plain dates resolve to the start of the day, or its end when endOfDay is set.
+func parseDate(value string, endOfDay bool, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC3339, got %q", value)
+	}
+
+	if endOfDay {
+		return endOf(t.AddDate(0, 0, 1)), nil
+	}
+	return t, nil
+}
+
+// endOf returns the last second before the given boundary, so windows stay inclusive
+// on both ends like GitHub's range qualifiers.
+func endOf(boundary time.Time) time.Time {
+	return boundary.Add(-time.Second)
+}
+
+// quarterStart returns the first month of the quarter containing m.
+func quarterStart(m time.Month) time.Month {
+	return time.Month((int(m)-1)/3*3 + 1)
+}
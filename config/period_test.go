@@ -0,0 +1,130 @@
+package config
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParsePeriodAcrossDST(t *testing.T) {
+	london := mustLoad(t, "Europe/London")
+	sydney := mustLoad(t, "Australia/Sydney")
+
+	tests := []struct {
+		name      string
+		period    string
+		now       time.Time
+		wantSince string
+		wantUntil string
+	}{
+		{
+			// Clocks go forward on Sunday 30 March 2025
+			name:      "london last-week spring forward",
+			period:    "last-week",
+			now:       time.Date(2025, time.April, 2, 12, 0, 0, 0, london),
+			wantSince: "2025-03-24T00:00:00Z",
+			wantUntil: "2025-03-30T23:59:59+01:00",
+		},
+		{
+			// Clocks go back on Sunday 26 October 2025
+			name:      "london last-month fall back",
+			period:    "last-month",
+			now:       time.Date(2025, time.November, 15, 9, 0, 0, 0, london),
+			wantSince: "2025-10-01T00:00:00+01:00",
+			wantUntil: "2025-10-31T23:59:59Z",
+		},
+		{
+			name:      "london year spans both transitions",
+			period:    "2025",
+			now:       time.Date(2026, time.February, 1, 0, 0, 0, 0, london),
+			wantSince: "2025-01-01T00:00:00Z",
+			wantUntil: "2025-12-31T23:59:59Z",
+		},
+		{
+			// Daylight saving ends on Sunday 6 April 2025
+			name:      "sydney quarter fall back",
+			period:    "2025-Q2",
+			now:       time.Date(2025, time.August, 1, 0, 0, 0, 0, sydney),
+			wantSince: "2025-04-01T00:00:00+11:00",
+			wantUntil: "2025-06-30T23:59:59+10:00",
+		},
+		{
+			// Daylight saving starts on Sunday 5 October 2025
+			name:      "sydney last-week spring forward",
+			period:    "last-week",
+			now:       time.Date(2025, time.October, 8, 8, 0, 0, 0, sydney),
+			wantSince: "2025-09-29T00:00:00+10:00",
+			wantUntil: "2025-10-05T23:59:59+11:00",
+		},
+		{
+			name:      "sydney H2 spring forward",
+			period:    "H2-2025",
+			now:       time.Date(2026, time.March, 1, 0, 0, 0, 0, sydney),
+			wantSince: "2025-07-01T00:00:00+10:00",
+			wantUntil: "2025-12-31T23:59:59+11:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, until, err := ParsePeriod(tt.period, tt.now)
+			if err != nil {
+				t.Fatalf("ParsePeriod(%q) error = %v", tt.period, err)
+			}
+			if got := since.Format(time.RFC3339); got != tt.wantSince {
+				t.Errorf("since = %s, want %s", got, tt.wantSince)
+			}
+			if got := until.Format(time.RFC3339); got != tt.wantUntil {
+				t.Errorf("until = %s, want %s", got, tt.wantUntil)
+			}
+		})
+	}
+}
+
+func TestParseDateAcrossDST(t *testing.T) {
+	london := mustLoad(t, "Europe/London")
+	sydney := mustLoad(t, "Australia/Sydney")
+
+	tests := []struct {
+		name      string
+		value     string
+		endOfDay  bool
+		loc       *time.Location
+		want      string
+		wantHours float64
+	}{
+		// The day clocks go forward has 23 hours, the day they go back 25
+		{name: "london spring forward", value: "2025-03-30", loc: london, want: "2025-03-30T00:00:00Z", wantHours: 23},
+		{name: "london spring forward end", value: "2025-03-30", endOfDay: true, loc: london, want: "2025-03-30T23:59:59+01:00", wantHours: 23},
+		{name: "london fall back end", value: "2025-10-26", endOfDay: true, loc: london, want: "2025-10-26T23:59:59Z", wantHours: 25},
+		{name: "sydney fall back", value: "2025-04-06", loc: sydney, want: "2025-04-06T00:00:00+11:00", wantHours: 25},
+		{name: "sydney spring forward end", value: "2025-10-05", endOfDay: true, loc: sydney, want: "2025-10-05T23:59:59+11:00", wantHours: 23},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDate(tt.value, tt.endOfDay, tt.loc)
+			if err != nil {
+				t.Fatalf("parseDate(%q) error = %v", tt.value, err)
+			}
+			if got.Format(time.RFC3339) != tt.want {
+				t.Errorf("parseDate(%q) = %s, want %s", tt.value, got.Format(time.RFC3339), tt.want)
+			}
+
+			start, _ := parseDate(tt.value, false, tt.loc)
+			end, _ := parseDate(tt.value, true, tt.loc)
+			if hours := end.Sub(start).Round(time.Hour).Hours(); hours != tt.wantHours {
+				t.Errorf("day %s lasts %v hours, want %v", tt.value, hours, tt.wantHours)
+			}
+		})
+	}
+}
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("loading %s: %v", name, err)
+	}
+	return loc
+}
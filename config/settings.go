@@ -0,0 +1,184 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors is every problem found with the configuration, so they can all be fixed in one go
+type Errors []error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d problems with the configuration:", len(e))
+	for _, err := range e {
+		fmt.Fprintf(&b, "\n  - %v", err)
+	}
+	return b.String()
+}
+
+func (e Errors) Unwrap() []error { return e }
+
+// settings reads typed values from the environment and config file. Problems are
+// collected rather than returned, and the setting falls back to its default.
+type settings struct {
+	file *File
+	errs Errors
+}
+
+func (s *settings) errorf(format string, args ...any) {
+	s.errs = append(s.errs, fmt.Errorf(format, args...))
+}
+
+// string returns the value of key, with blank values treated as unset
+func (s *settings) string(key string) string {
+	return strings.TrimSpace(s.file.Getenv(key))
+}
+
+// required returns the value of a setting that has no default
+func (s *settings) required(key string) string {
+	value := s.string(key)
+	if value == "" {
+		s.errorf("%s is not set", key)
+	}
+	return value
+}
+
+// oneOf returns the value of key, which must be one of allowed
+func (s *settings) oneOf(key, fallback string, allowed ...string) string {
+	value := s.string(key)
+	if value == "" {
+		return fallback
+	}
+	for _, option := range allowed {
+		if value == option {
+			return value
+		}
+	}
+	s.errorf("unknown %s %q, expected %s", key, value, strings.Join(allowed, " or "))
+	return fallback
+}
+
+func (s *settings) boolean(key string) bool {
+	value := s.string(key)
+	if value == "" {
+		return false
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		s.errorf("invalid %s value %q: expected true or false", key, value)
+		return false
+	}
+	return parsed
+}
+
+// integer returns the value of key, which must be a whole number of at least min
+func (s *settings) integer(key string, fallback, min int) int {
+	value := s.string(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		s.errorf("invalid %s value %q: expected a whole number", key, value)
+		return fallback
+	}
+	if parsed < min {
+		s.errorf("invalid %s value %d: must be at least %d", key, parsed, min)
+		return fallback
+	}
+	return parsed
+}
+
+// float returns the value of key, which must lie between min and max
+func (s *settings) float(key string, fallback, min, max float64) float64 {
+	value := s.string(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		s.errorf("invalid %s value %q: expected a number", key, value)
+		return fallback
+	}
+	if parsed < min || parsed > max {
+		s.errorf("invalid %s value %g: must be between %g and %g", key, parsed, min, max)
+		return fallback
+	}
+	return parsed
+}
+
+func (s *settings) duration(key string, fallback time.Duration) time.Duration {
+	value := s.string(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		s.errorf("invalid %s value %q: expected a duration such as 15m or 2h", key, value)
+		return fallback
+	}
+	return parsed
+}
+
+// path returns the value of key resolved against the workspace, or "" when unset
+func (s *settings) path(key string) string {
+	value := s.string(key)
+	if value == "" {
+		return ""
+	}
+	return workspacePath(value)
+}
+
+// existingFile returns the path in key, which must name a readable file
+func (s *settings) existingFile(key string) string {
+	path := s.path(key)
+	if path == "" {
+		return ""
+	}
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		s.errorf("%s: %v", key, err)
+	case info.IsDir():
+		s.errorf("%s: %s is a directory, not a file", key, path)
+	}
+	return path
+}
+
+// checkWritable reports whether a file can be written at path, without changing it
+func checkWritable(path string) error {
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory", path)
+		}
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if info, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("directory %s does not exist", dir)
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	probe, err := os.CreateTemp(dir, ".git-log-*")
+	if err != nil {
+		return fmt.Errorf("directory %s is not writable", dir)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
@@ -0,0 +1,240 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"git-log/internal/processing"
+)
+
+// Supported export formats
+const (
+	FormatJSON   = "json"
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Formats are every supported format, in the order files are written
+var Formats = []string{FormatJSON, FormatCSV, FormatNDJSON}
+
+// Event is a single piece of activity, written one per line in NDJSON
+type Event struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	Repository string    `json:"repository"`
+	Number     int       `json:"number,omitempty"`
+	SHA        string    `json:"sha,omitempty"`
+	Title      string    `json:"title"`
+	URL        string    `json:"url"`
+}
+
+// Event types
+const (
+	EventPullRequestOpened = "pull_request_opened"
+	EventPullRequestMerged = "pull_request_merged"
+	EventPullRequestClosed = "pull_request_closed"
+	EventCommit            = "commit"
+	EventReview            = "review"
+)
+
+// Write writes the work log to dir in each of the given formats and returns the files written:
+// work_log.json, pull_requests.csv and commits.csv, and events.ndjson
+func Write(dir string, workLog processing.WorkLog, formats []string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating export directory: %w", err)
+	}
+
+	var written []string
+	write := func(name string, fn func(io.Writer, processing.WorkLog) error) error {
+		path := filepath.Join(dir, name)
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", name, err)
+		}
+		if err := fn(file, workLog); err != nil {
+			file.Close()
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		written = append(written, path)
+		return nil
+	}
+
+	for _, format := range formats {
+		var err error
+		switch format {
+		case FormatJSON:
+			err = write("work_log.json", WriteJSON)
+		case FormatCSV:
+			err = write("pull_requests.csv", WritePullRequestsCSV)
+			if err == nil {
+				err = write("commits.csv", WriteCommitsCSV)
+			}
+		case FormatNDJSON:
+			err = write("events.ndjson", WriteEvents)
+		default:
+			err = fmt.Errorf("unknown export format %q, expected one of: %s", format, strings.Join(Formats, ", "))
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// WriteJSON writes the work log in the same layout as data/example_work_log.json
+func WriteJSON(w io.Writer, workLog processing.WorkLog) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(workLog)
+}
+
+// WritePullRequestsCSV writes one row per pull request. Authored PRs and reviewed PRs
+// share the file and are told apart by the role column.
+func WritePullRequestsCSV(w io.Writer, workLog processing.WorkLog) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"repository", "role", "number", "title", "state", "draft", "labels",
+		"created_at", "updated_at", "closed_at", "merged_at", "comments", "url",
+	}); err != nil {
+		return err
+	}
+
+	for _, repo := range workLog.Repositories {
+		rows := [][]processing.PullRequest{repo.PullRequests, repo.Reviews}
+		for i, role := range []string{"author", "reviewer"} {
+			for _, pr := range rows[i] {
+				if err := writer.Write([]string{
+					repo.FullName,
+					role,
+					strconv.Itoa(pr.Number),
+					pr.Title,
+					pr.State,
+					strconv.FormatBool(pr.IsDraft),
+					strings.Join(pr.Labels, ";"),
+					formatTime(&pr.CreatedAt),
+					formatTime(&pr.UpdatedAt),
+					formatTime(pr.ClosedAt),
+					formatTime(pr.MergedAt),
+					strconv.Itoa(pr.Comments),
+					pr.URL,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteCommitsCSV writes one row per commit with the subject line and the full message
+func WriteCommitsCSV(w io.Writer, workLog processing.WorkLog) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"repository", "sha", "date", "subject", "message", "url"}); err != nil {
+		return err
+	}
+
+	for _, repo := range workLog.Repositories {
+		for _, commit := range repo.Commits {
+			subject, _, _ := strings.Cut(commit.Message, "\n")
+			if err := writer.Write([]string{
+				repo.FullName,
+				commit.SHA,
+				formatTime(&commit.Date),
+				subject,
+				commit.Message,
+				commit.URL,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteEvents writes the activity as a time-ordered stream of events, one JSON object per line
+func WriteEvents(w io.Writer, workLog processing.WorkLog) error {
+	encoder := json.NewEncoder(w)
+	for _, event := range Events(workLog) {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Events flattens the work log into events, oldest first
+func Events(workLog processing.WorkLog) []Event {
+	var events []Event
+
+	for _, repo := range workLog.Repositories {
+		for _, pr := range repo.PullRequests {
+			event := Event{Repository: repo.FullName, Number: pr.Number, Title: pr.Title, URL: pr.URL}
+
+			opened := event
+			opened.Type, opened.Time = EventPullRequestOpened, pr.CreatedAt
+			events = append(events, opened)
+
+			switch {
+			case pr.MergedAt != nil:
+				event.Type, event.Time = EventPullRequestMerged, *pr.MergedAt
+				events = append(events, event)
+			case pr.ClosedAt != nil:
+				event.Type, event.Time = EventPullRequestClosed, *pr.ClosedAt
+				events = append(events, event)
+			}
+		}
+
+		for _, commit := range repo.Commits {
+			subject, _, _ := strings.Cut(commit.Message, "\n")
+			events = append(events, Event{
+				Type:       EventCommit,
+				Time:       commit.Date,
+				Repository: repo.FullName,
+				SHA:        commit.SHA,
+				Title:      subject,
+				URL:        commit.URL,
+			})
+		}
+
+		// The search API doesn't say when a review was submitted, so the PR's last update stands in
+		for _, pr := range repo.Reviews {
+			events = append(events, Event{
+				Type:       EventReview,
+				Time:       pr.UpdatedAt,
+				Repository: repo.FullName,
+				Number:     pr.Number,
+				Title:      pr.Title,
+				URL:        pr.URL,
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events
+}
+
+// formatTime formats t as RFC 3339, or an empty cell when it is unset
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache stores API responses on disk keyed by URL and token, so a response is never served
+// to a token that may not be allowed to see it. Fresh entries are served without
+// a request; stale ones are revalidated with If-None-Match / If-Modified-Since, and
+// 304 responses don't count against the primary rate limit.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+// cacheEntry is a single cached response
+type cacheEntry struct {
+	URL string `json:"url"`
+	// TokenHash identifies the token the response was fetched with without storing it
+	TokenHash    string    `json:"token_hash"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	Body         []byte    `json:"body"`
+}
+
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{
+		Dir: dir,
+		TTL: ttl,
+	}
+}
+
+// fresh reports whether the entry can be served without revalidation
+func (c *Cache) fresh(entry *cacheEntry) bool {
+	return time.Since(entry.FetchedAt) < c.TTL
+}
+
+// get returns the entry cached for url and token, or nil if there is none
+func (c *Cache) get(url, token string) *cacheEntry {
+	hash := tokenHash(token)
+	data, err := os.ReadFile(c.path(url, hash))
+	if err != nil {
+		return nil
+	}
+
+	var entry cacheEntry
+	// A corrupt or colliding entry is treated as a miss
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url || entry.TokenHash != hash {
+		return nil
+	}
+	return &entry
+}
+
+// put writes the entry to disk
+func (c *Cache) put(entry *cacheEntry) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(entry.URL, entry.TokenHash)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func (c *Cache) path(url, tokenHash string) string {
+	sum := sha256.Sum256([]byte(tokenHash + " " + url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,42 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCacheIsKeyedByToken(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	cache := NewCache(t.TempDir(), time.Hour)
+	get := func(token string) string {
+		client := &Client{Token: token, HTTPClient: server.Client(), BaseURL: server.URL, Cache: cache}
+		body, err := client.makeRequest(context.Background(), server.URL+"/user/repos")
+		if err != nil {
+			t.Fatalf("makeRequest() error = %v", err)
+		}
+		return string(body)
+	}
+
+	if got := get("first"); got != "token first" {
+		t.Errorf("first token got %q", got)
+	}
+	if got := get("second"); got != "token second" {
+		t.Errorf("second token got %q, want its own response", got)
+	}
+	if got := get("first"); got != "token first" {
+		t.Errorf("first token got %q from the cache", got)
+	}
+	if requests != 2 {
+		t.Errorf("made %d requests, want 2", requests)
+	}
+}
@@ -12,6 +12,8 @@ type Client struct {
 	Token      string
 	HTTPClient *http.Client
 	BaseURL    string
+	// Cache is optional; responses are not cached when nil
+	Cache *Cache
 }
 
 func NewClient(token string) *Client {
@@ -23,6 +25,14 @@ func NewClient(token string) *Client {
 }
 
 func (c *Client) makeRequest(ctx context.Context, url string) ([]byte, error) {
+	var cached *cacheEntry
+	if c.Cache != nil {
+		cached = c.Cache.get(url, c.Token)
+		if cached != nil && c.Cache.fresh(cached) {
+			return cached.Body, nil
+		}
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -31,12 +41,30 @@ func (c *Client) makeRequest(ctx context.Context, url string) ([]byte, error) {
 	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.Token))
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
+	// Revalidate a stale entry instead of downloading it again
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		cached.FetchedAt = time.Now()
+		if err := c.Cache.put(cached); err != nil {
+			fmt.Printf("Warning: Failed to cache response: %v\n", err)
+		}
+		return cached.Body, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("API request failed with status: %d, body: %s",
@@ -48,5 +76,24 @@ func (c *Client) makeRequest(ctx context.Context, url string) ([]byte, error) {
 		return nil, err
 	}
 
+	if c.Cache != nil {
+		entry := &cacheEntry{
+			URL:          url,
+			TokenHash:    tokenHash(c.Token),
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    time.Now(),
+			Body:         body,
+		}
+		if err := c.Cache.put(entry); err != nil {
+			fmt.Printf("Warning: Failed to cache response: %v\n", err)
+		}
+	}
+
 	return body, nil
 }
+
+// dateRange formats an inclusive search qualifier range, e.g. "2025-01-01T00:00:00Z..2025-03-31T23:59:59Z"
+func dateRange(since, until time.Time) string {
+	return fmt.Sprintf("%s..%s", since.Format(time.RFC3339), until.Format(time.RFC3339))
+}
@@ -2,11 +2,11 @@ package github
 
 import "time"
 
-// IssueSearchResult represents the response from GitHub's issue search API
-type IssueSearchResult struct {
-	TotalCount        int                     `json:"total_count"`
-	IncompleteResults bool                    `json:"incomplete_results"`
-	Items             []IssueSearchResultItem `json:"items"`
+// SearchResult represents a page of results from GitHub's issue or commit search API
+type SearchResult[T any] struct {
+	TotalCount        int  `json:"total_count"`
+	IncompleteResults bool `json:"incomplete_results"`
+	Items             []T  `json:"items"`
 }
 
 // IssueSearchResultItem represents a single issue in the search results
@@ -101,13 +101,6 @@ type IssueType struct {
 // COMMIT SEARCHES //
 //-----------------//
 
-// CommitSearchResult represents the response from GitHub's commit search API
-type CommitSearchResult struct {
-	TotalCount        int                      `json:"total_count"`
-	IncompleteResults bool                     `json:"incomplete_results"`
-	Items             []CommitSearchResultItem `json:"items"`
-}
-
 // CommitSearchResultItem represents a single commit in the search results
 type CommitSearchResultItem struct {
 	URL         string       `json:"url"`
@@ -135,3 +128,26 @@ type GitUser struct {
 	Email string `json:"email"`
 	Date  string `json:"date"`
 }
+
+//---------------//
+// PULL REQUESTS //
+//---------------//
+
+// PullRequestDetail is the subset of a full pull request that search results lack
+type PullRequestDetail struct {
+	ID           int64      `json:"id"`
+	Number       int        `json:"number"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	MergedAt     *time.Time `json:"merged_at"`
+	Commits      int        `json:"commits"`
+	Additions    int        `json:"additions"`
+	Deletions    int        `json:"deletions"`
+	ChangedFiles int        `json:"changed_files"`
+}
+
+// PullRequestCommit is a commit listed on a pull request
+type PullRequestCommit struct {
+	SHA     string       `json:"sha"`
+	HTMLURL string       `json:"html_url"`
+	Commit  CommitDetail `json:"commit"`
+}
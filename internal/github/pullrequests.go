@@ -4,32 +4,54 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"time"
 )
 
-func (c *Client) GetPullRequests(ctx context.Context, author string, since time.Time) ([]IssueSearchResultItem, error) {
-	query := fmt.Sprintf("is:pr author:%s created:>%s", author, since.Format(time.RFC3339))
+// GetPullRequests returns the pull requests authored by author that were created within the window
+func (c *Client) GetPullRequests(ctx context.Context, author string, since, until time.Time) ([]IssueSearchResultItem, error) {
+	return search[IssueSearchResultItem](ctx, c, "issues", "is:pr author:"+author, "created", since, until)
+}
+
+// GetUpdatedPullRequests returns the pull requests authored by author that were updated within the window.
+// Unlike GetPullRequests this picks up older PRs that were merged or closed in the meantime.
+func (c *Client) GetUpdatedPullRequests(ctx context.Context, author string, since, until time.Time) ([]IssueSearchResultItem, error) {
+	return search[IssueSearchResultItem](ctx, c, "issues", "is:pr author:"+author, "updated", since, until)
+}
 
-	// Build URL with properly encoded query parameters
-	baseURL := fmt.Sprintf("%s/search/issues", c.BaseURL)
-	params := url.Values{}
-	params.Add("q", query)
-	params.Add("per_page", "100")
-	params.Add("sort", "created")
-	params.Add("order", "desc")
+// GetReviewedPullRequests returns other people's pull requests reviewed by reviewer that were updated within the window
+func (c *Client) GetReviewedPullRequests(ctx context.Context, reviewer string, since, until time.Time) ([]IssueSearchResultItem, error) {
+	query := fmt.Sprintf("is:pr reviewed-by:%s -author:%s", reviewer, reviewer)
+	return search[IssueSearchResultItem](ctx, c, "issues", query, "updated", since, until)
+}
 
-	requestURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
+// GetPullRequest returns the details of a single pull request, including its diffstat
+func (c *Client) GetPullRequest(ctx context.Context, fullName string, number int) (*PullRequestDetail, error) {
+	requestURL := fmt.Sprintf("%s/repos/%s/pulls/%d", c.BaseURL, fullName, number)
 
 	body, err := c.makeRequest(ctx, requestURL)
 	if err != nil {
 		return nil, err
 	}
 
-	var response IssueSearchResult
-	if err := json.Unmarshal(body, &response); err != nil {
+	var detail PullRequestDetail
+	if err := json.Unmarshal(body, &detail); err != nil {
 		return nil, err
 	}
+	return &detail, nil
+}
+
+// GetPullRequestCommits returns the commits of a pull request, up to the first 100
+func (c *Client) GetPullRequestCommits(ctx context.Context, fullName string, number int) ([]PullRequestCommit, error) {
+	requestURL := fmt.Sprintf("%s/repos/%s/pulls/%d/commits?per_page=100", c.BaseURL, fullName, number)
 
-	return response.Items, nil
+	body, err := c.makeRequest(ctx, requestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []PullRequestCommit
+	if err := json.Unmarshal(body, &commits); err != nil {
+		return nil, err
+	}
+	return commits, nil
 }
@@ -0,0 +1,74 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	// searchPageSize is the most results a search returns per page
+	searchPageSize = 100
+	// searchLimit is the most results GitHub returns for a single search query, however many pages are requested
+	searchLimit = 1000
+)
+
+// search returns every result of a search for query within the window, where qualifier is the
+// date field the window applies to and the results are sorted by. Every page is fetched, and a
+// window with more results than a single query returns is split in half and searched again.
+func search[T any](ctx context.Context, c *Client, endpoint, query, qualifier string, since, until time.Time) ([]T, error) {
+	var items []T
+	for page := 1; ; page++ {
+		result, err := searchPage[T](ctx, c, endpoint, query, qualifier, since, until, page)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 1 && result.TotalCount > searchLimit {
+			if until.Sub(since) >= 2*time.Second {
+				mid := since.Add(until.Sub(since) / 2).Truncate(time.Second)
+				older, err := search[T](ctx, c, endpoint, query, qualifier, since, mid)
+				if err != nil {
+					return nil, err
+				}
+				newer, err := search[T](ctx, c, endpoint, query, qualifier, mid.Add(time.Second), until)
+				if err != nil {
+					return nil, err
+				}
+				return append(newer, older...), nil
+			}
+			fmt.Printf("Warning: %d results for %q within one second, only the first %d are fetched\n",
+				result.TotalCount, query, searchLimit)
+		}
+
+		items = append(items, result.Items...)
+		if len(result.Items) < searchPageSize || len(items) >= result.TotalCount || len(items) >= searchLimit {
+			return items, nil
+		}
+	}
+}
+
+// searchPage fetches one page of search results
+func searchPage[T any](ctx context.Context, c *Client, endpoint, query, qualifier string, since, until time.Time, page int) (*SearchResult[T], error) {
+	params := url.Values{}
+	params.Add("q", fmt.Sprintf("%s %s:%s", query, qualifier, dateRange(since, until)))
+	params.Add("per_page", fmt.Sprint(searchPageSize))
+	params.Add("page", fmt.Sprint(page))
+	params.Add("sort", qualifier)
+	params.Add("order", "desc")
+
+	requestURL := fmt.Sprintf("%s/search/%s?%s", c.BaseURL, endpoint, params.Encode())
+
+	body, err := c.makeRequest(ctx, requestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var result SearchResult[T]
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,93 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// searchServer serves issue searches over items created one hour apart, paging and
+// capping the results the way GitHub does
+func searchServer(t *testing.T, start time.Time, count int) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query := r.URL.Query()
+		_, window, ok := strings.Cut(query.Get("q"), "created:")
+		if !ok {
+			t.Errorf("query %q has no created qualifier", query.Get("q"))
+		}
+		fromText, toText, _ := strings.Cut(window, "..")
+		from, err := time.Parse(time.RFC3339, fromText)
+		if err != nil {
+			t.Fatal(err)
+		}
+		to, err := time.Parse(time.RFC3339, toText)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var matches []IssueSearchResultItem
+		for i := count - 1; i >= 0; i-- {
+			created := start.Add(time.Duration(i) * time.Hour)
+			if !created.Before(from) && !created.After(to) {
+				matches = append(matches, IssueSearchResultItem{ID: int64(i), CreatedAt: created})
+			}
+		}
+
+		page, _ := strconv.Atoi(query.Get("page"))
+		perPage, _ := strconv.Atoi(query.Get("per_page"))
+		result := SearchResult[IssueSearchResultItem]{TotalCount: len(matches)}
+		first := (page - 1) * perPage
+		if first < min(len(matches), searchLimit) {
+			result.Items = matches[first:min(first+perPage, len(matches), searchLimit)]
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestSearchFetchesEveryPage(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	server, requests := searchServer(t, start, 250)
+	client := &Client{HTTPClient: server.Client(), BaseURL: server.URL}
+
+	items, err := client.GetPullRequests(context.Background(), "jdoe", start, start.Add(1000*time.Hour))
+	if err != nil {
+		t.Fatalf("GetPullRequests() error = %v", err)
+	}
+	if len(items) != 250 {
+		t.Errorf("got %d items, want 250", len(items))
+	}
+	if *requests != 3 {
+		t.Errorf("made %d requests, want 3", *requests)
+	}
+}
+
+func TestSearchSplitsWindowsOverTheLimit(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	server, _ := searchServer(t, start, 2500)
+	client := &Client{HTTPClient: server.Client(), BaseURL: server.URL}
+
+	items, err := client.GetPullRequests(context.Background(), "jdoe", start, start.Add(3000*time.Hour))
+	if err != nil {
+		t.Fatalf("GetPullRequests() error = %v", err)
+	}
+
+	seen := make(map[int64]bool)
+	for _, item := range items {
+		if seen[item.ID] {
+			t.Errorf("item %d returned twice", item.ID)
+		}
+		seen[item.ID] = true
+	}
+	if len(seen) != 2500 {
+		t.Errorf("got %d distinct items, want 2500", len(seen))
+	}
+}
@@ -0,0 +1,64 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// TokenInfo describes the account and permissions of the client's token
+type TokenInfo struct {
+	Login string
+	// Scopes are the OAuth scopes of a classic token. Fine-grained tokens have none;
+	// their access is set per repository.
+	Scopes []string
+	// Classic is false for fine-grained tokens and GitHub App tokens
+	Classic bool
+}
+
+// GetTokenInfo fetches the authenticated user, which is the cheapest way to see what a token can do.
+// It bypasses the cache, since the scopes are only reported in the response headers.
+func (c *Client) GetTokenInfo(ctx context.Context) (*TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.Token))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API request failed with status: %d, body: %s",
+			resp.StatusCode, string(body))
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.Unmarshal(body, &user); err != nil {
+		return nil, err
+	}
+
+	info := &TokenInfo{Login: user.Login}
+	if header, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		info.Classic = true
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				info.Scopes = append(info.Scopes, scope)
+			}
+		}
+	}
+	return info, nil
+}
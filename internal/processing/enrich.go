@@ -0,0 +1,53 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"git-log/internal/github"
+)
+
+// EnrichPullRequests adds the diffstat and commits of the authored pull requests selected by
+// want, or of all of them when want is nil, which the search API doesn't return. Each PR costs
+// two requests. A PR whose details can't be fetched is left as it is and the failures are
+// returned together.
+func EnrichPullRequests(ctx context.Context, client *github.Client, workLog *WorkLog, want func(repository string, pr PullRequest) bool) error {
+	var errs []error
+
+	for i := range workLog.Repositories {
+		repo := &workLog.Repositories[i]
+		for j := range repo.PullRequests {
+			pr := &repo.PullRequests[j]
+			if want != nil && !want(repo.FullName, *pr) {
+				continue
+			}
+
+			detail, err := client.GetPullRequest(ctx, repo.FullName, pr.Number)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s#%d: %w", repo.FullName, pr.Number, err))
+				continue
+			}
+			pr.Additions = detail.Additions
+			pr.Deletions = detail.Deletions
+			pr.ChangedFiles = detail.ChangedFiles
+
+			commits, err := client.GetPullRequestCommits(ctx, repo.FullName, pr.Number)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s#%d commits: %w", repo.FullName, pr.Number, err))
+				continue
+			}
+			pr.CommitMessages = make([]string, 0, len(commits))
+			pr.CommitSHAs = make([]string, 0, len(commits))
+			for _, commit := range commits {
+				pr.CommitSHAs = append(pr.CommitSHAs, commit.SHA)
+				// The subject line is enough to tell what a commit did
+				subject, _, _ := strings.Cut(commit.Commit.Message, "\n")
+				pr.CommitMessages = append(pr.CommitMessages, subject)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
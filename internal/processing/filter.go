@@ -2,6 +2,8 @@ package processing
 
 import (
 	"git-log/internal/github"
+	"path"
+	"strings"
 	"time"
 )
 
@@ -11,6 +13,7 @@ func FilterPullRequests(items []github.IssueSearchResultItem) []PullRequest {
 
 	for _, item := range items {
 		pr := PullRequest{
+			ID:        item.ID,
 			Number:    item.Number,
 			Title:     item.Title,
 			Body:      item.Body,
@@ -82,3 +85,39 @@ func ExtractRepositoryInfo(repo github.Repository) (name, fullName, description,
 
 	return
 }
+
+// RepoFilter selects repositories by full name. Patterns use path.Match syntax, so
+// "acme/*" matches every repository of the acme organization. Matching ignores case.
+type RepoFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Match reports whether the repository passes the filter. No include patterns include everything.
+func (f RepoFilter) Match(fullName string) bool {
+	return (len(f.Include) == 0 || matchAny(f.Include, fullName)) && !matchAny(f.Exclude, fullName)
+}
+
+func matchAny(patterns []string, fullName string) bool {
+	fullName = strings.ToLower(fullName)
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), fullName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterRepositories drops the repositories that don't pass the filter and recomputes the totals
+func FilterRepositories(workLog *WorkLog, filter RepoFilter) *WorkLog {
+	repositories := make([]RepositoryActivity, 0, len(workLog.Repositories))
+	for _, repo := range workLog.Repositories {
+		if filter.Match(repo.FullName) {
+			repositories = append(repositories, repo)
+		}
+	}
+
+	summary := generateSummary(repositories)
+	summary.Period = workLog.Summary.Period
+	return &WorkLog{Repositories: repositories, Summary: summary}
+}
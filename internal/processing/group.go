@@ -7,50 +7,40 @@ import (
 	"time"
 )
 
-// GroupByRepository organizes pull requests and commits by their repositories
-func GroupByRepository(prs []github.IssueSearchResultItem, commits []github.CommitSearchResultItem) *WorkLog {
+// GroupByRepository organizes pull requests, commits and reviewed pull requests by their repositories.
+// All timestamps are converted to opts.Location so dates fall on the user's calendar.
+func GroupByRepository(prs []github.IssueSearchResultItem, commits []github.CommitSearchResultItem, reviews []github.IssueSearchResultItem, opts Options) *WorkLog {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
 	repoMap := make(map[string]*RepositoryActivity)
 
 	// Process pull requests
 	for _, pr := range prs {
-		repoFullName := pr.Repository.FullName
-
-		// If repository info is empty, try to extract from PR URL
-		if repoFullName == "" && pr.HTMLURL != "" {
-			repoFullName = extractRepoFromURL(pr.HTMLURL)
-		}
-
-		// Skip if we still can't determine the repository
-		if repoFullName == "" {
+		repo := pullRequestRepository(repoMap, pr)
+		if repo == nil {
 			continue
 		}
 
-		// Initialize repository if not exists
-		if _, exists := repoMap[repoFullName]; !exists {
-			name, fullName, description, url, language := ExtractRepositoryInfo(pr.Repository)
-
-			// If extraction failed, use info from URL
-			if fullName == "" {
-				fullName = repoFullName
-				name = extractRepoNameFromFullName(repoFullName)
-				url = "https://github.com/" + repoFullName
-			}
+		// Add filtered PR to repository
+		filteredPRs := FilterPullRequests([]github.IssueSearchResultItem{pr})
+		if len(filteredPRs) > 0 {
+			repo.PullRequests = append(repo.PullRequests, localizePullRequest(filteredPRs[0], loc))
+		}
+	}
 
-			repoMap[repoFullName] = &RepositoryActivity{
-				Name:         name,
-				FullName:     fullName,
-				Description:  description,
-				URL:          url,
-				Language:     language,
-				PullRequests: []PullRequest{},
-				Commits:      []Commit{},
-			}
+	// Process reviewed pull requests
+	for _, pr := range reviews {
+		repo := pullRequestRepository(repoMap, pr)
+		if repo == nil {
+			continue
 		}
 
-		// Add filtered PR to repository
 		filteredPRs := FilterPullRequests([]github.IssueSearchResultItem{pr})
 		if len(filteredPRs) > 0 {
-			repoMap[repoFullName].PullRequests = append(repoMap[repoFullName].PullRequests, filteredPRs[0])
+			repo.Reviews = append(repo.Reviews, localizePullRequest(filteredPRs[0], loc))
 		}
 	}
 
@@ -83,7 +73,9 @@ func GroupByRepository(prs []github.IssueSearchResultItem, commits []github.Comm
 		// Add filtered commit to repository
 		filteredCommits := FilterCommits([]github.CommitSearchResultItem{commit})
 		if len(filteredCommits) > 0 {
-			repoMap[repoFullName].Commits = append(repoMap[repoFullName].Commits, filteredCommits[0])
+			commit := filteredCommits[0]
+			commit.Date = commit.Date.In(loc)
+			repoMap[repoFullName].Commits = append(repoMap[repoFullName].Commits, commit)
 		}
 	}
 
@@ -95,6 +87,11 @@ func GroupByRepository(prs []github.IssueSearchResultItem, commits []github.Comm
 			return repo.PullRequests[i].CreatedAt.After(repo.PullRequests[j].CreatedAt)
 		})
 
+		// Sort reviews by last update (newest first)
+		sort.Slice(repo.Reviews, func(i, j int) bool {
+			return repo.Reviews[i].UpdatedAt.After(repo.Reviews[j].UpdatedAt)
+		})
+
 		// Sort commits by date (newest first)
 		sort.Slice(repo.Commits, func(i, j int) bool {
 			return repo.Commits[i].Date.After(repo.Commits[j].Date)
@@ -110,6 +107,10 @@ func GroupByRepository(prs []github.IssueSearchResultItem, commits []github.Comm
 
 	// Generate summary
 	summary := generateSummary(repositories)
+	summary.Period = DateRange{
+		Start: opts.Period.Start.In(loc),
+		End:   opts.Period.End.In(loc),
+	}
 
 	return &WorkLog{
 		Repositories: repositories,
@@ -117,6 +118,61 @@ func GroupByRepository(prs []github.IssueSearchResultItem, commits []github.Comm
 	}
 }
 
+// pullRequestRepository returns the activity entry for the PR's repository, creating it if needed.
+// It returns nil when the repository cannot be determined.
+func pullRequestRepository(repoMap map[string]*RepositoryActivity, pr github.IssueSearchResultItem) *RepositoryActivity {
+	repoFullName := pr.Repository.FullName
+
+	// If repository info is empty, try to extract from PR URL
+	if repoFullName == "" && pr.HTMLURL != "" {
+		repoFullName = extractRepoFromURL(pr.HTMLURL)
+	}
+
+	// Skip if we still can't determine the repository
+	if repoFullName == "" {
+		return nil
+	}
+
+	// Initialize repository if not exists
+	if _, exists := repoMap[repoFullName]; !exists {
+		name, fullName, description, url, language := ExtractRepositoryInfo(pr.Repository)
+
+		// If extraction failed, use info from URL
+		if fullName == "" {
+			fullName = repoFullName
+			name = extractRepoNameFromFullName(repoFullName)
+			url = "https://github.com/" + repoFullName
+		}
+
+		repoMap[repoFullName] = &RepositoryActivity{
+			Name:         name,
+			FullName:     fullName,
+			Description:  description,
+			URL:          url,
+			Language:     language,
+			PullRequests: []PullRequest{},
+			Commits:      []Commit{},
+		}
+	}
+
+	return repoMap[repoFullName]
+}
+
+// localizePullRequest converts all PR timestamps to loc
+func localizePullRequest(pr PullRequest, loc *time.Location) PullRequest {
+	pr.CreatedAt = pr.CreatedAt.In(loc)
+	pr.UpdatedAt = pr.UpdatedAt.In(loc)
+	if pr.ClosedAt != nil {
+		closedAt := pr.ClosedAt.In(loc)
+		pr.ClosedAt = &closedAt
+	}
+	if pr.MergedAt != nil {
+		mergedAt := pr.MergedAt.In(loc)
+		pr.MergedAt = &mergedAt
+	}
+	return pr
+}
+
 // generateSummary creates summary statistics for the work log
 func generateSummary(repos []RepositoryActivity) Summary {
 	summary := Summary{
@@ -129,6 +185,7 @@ func generateSummary(repos []RepositoryActivity) Summary {
 	for _, repo := range repos {
 		summary.TotalPullRequests += len(repo.PullRequests)
 		summary.TotalCommits += len(repo.Commits)
+		summary.TotalReviews += len(repo.Reviews)
 
 		// Track date range from PRs
 		for _, pr := range repo.PullRequests {
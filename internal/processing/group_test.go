@@ -0,0 +1,62 @@
+package processing
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestLocalizePullRequestAcrossDST(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		utc      string
+		want     string
+	}{
+		{name: "london before spring forward", timezone: "Europe/London", utc: "2025-03-30T00:30:00Z", want: "2025-03-30T00:30:00Z"},
+		{name: "london after spring forward", timezone: "Europe/London", utc: "2025-03-30T01:30:00Z", want: "2025-03-30T02:30:00+01:00"},
+		{name: "london before fall back", timezone: "Europe/London", utc: "2025-10-26T00:30:00Z", want: "2025-10-26T01:30:00+01:00"},
+		{name: "london after fall back", timezone: "Europe/London", utc: "2025-10-26T01:30:00Z", want: "2025-10-26T01:30:00Z"},
+		// Late on the 5th in UTC is already the 6th in Sydney, on either side of the change
+		{name: "sydney before fall back", timezone: "Australia/Sydney", utc: "2025-04-05T15:30:00Z", want: "2025-04-06T02:30:00+11:00"},
+		{name: "sydney after fall back", timezone: "Australia/Sydney", utc: "2025-04-05T16:30:00Z", want: "2025-04-06T02:30:00+10:00"},
+		{name: "sydney before spring forward", timezone: "Australia/Sydney", utc: "2025-10-04T15:30:00Z", want: "2025-10-05T01:30:00+10:00"},
+		{name: "sydney after spring forward", timezone: "Australia/Sydney", utc: "2025-10-04T16:30:00Z", want: "2025-10-05T03:30:00+11:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := time.LoadLocation(tt.timezone)
+			if err != nil {
+				t.Fatalf("loading %s: %v", tt.timezone, err)
+			}
+			at, err := time.Parse(time.RFC3339, tt.utc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pr := localizePullRequest(PullRequest{CreatedAt: at, UpdatedAt: at, ClosedAt: &at, MergedAt: &at}, loc)
+
+			for field, got := range map[string]time.Time{
+				"CreatedAt": pr.CreatedAt,
+				"UpdatedAt": pr.UpdatedAt,
+				"ClosedAt":  *pr.ClosedAt,
+				"MergedAt":  *pr.MergedAt,
+			} {
+				if got.Format(time.RFC3339) != tt.want {
+					t.Errorf("%s = %s, want %s", field, got.Format(time.RFC3339), tt.want)
+				}
+				if !got.Equal(at) {
+					t.Errorf("%s moved from %s to %s", field, at, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalizePullRequestKeepsOpenPullRequestsOpen(t *testing.T) {
+	pr := localizePullRequest(PullRequest{CreatedAt: time.Now()}, time.UTC)
+	if pr.ClosedAt != nil || pr.MergedAt != nil {
+		t.Errorf("open pull request got ClosedAt %v and MergedAt %v", pr.ClosedAt, pr.MergedAt)
+	}
+}
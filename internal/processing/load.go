@@ -0,0 +1,31 @@
+package processing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadWorkLog reads a work log saved as JSON, such as an export or data/example_work_log.json.
+// Totals are recomputed from the repositories, and a missing reporting period falls back to
+// the range the activity covers, so hand-edited files still produce sensible prompts.
+func LoadWorkLog(path string) (*WorkLog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading work log: %w", err)
+	}
+
+	var workLog WorkLog
+	if err := json.Unmarshal(data, &workLog); err != nil {
+		return nil, fmt.Errorf("decoding work log %s: %w", path, err)
+	}
+
+	period := workLog.Summary.Period
+	workLog.Summary = generateSummary(workLog.Repositories)
+	workLog.Summary.Period = period
+	if period.Start.IsZero() || period.End.IsZero() {
+		workLog.Summary.Period = workLog.Summary.DateRange
+	}
+
+	return &workLog, nil
+}
@@ -16,11 +16,13 @@ type RepositoryActivity struct {
 	URL          string        `json:"url"`
 	PullRequests []PullRequest `json:"pull_requests,omitempty"`
 	Commits      []Commit      `json:"commits,omitempty"`
+	Reviews      []PullRequest `json:"reviews,omitempty"`
 	Language     string        `json:"language,omitempty"`
 }
 
 // PullRequest represents essential PR information
 type PullRequest struct {
+	ID        int64      `json:"id,omitempty"`
 	Number    int        `json:"number"`
 	Title     string     `json:"title"`
 	Body      string     `json:"body"`
@@ -33,6 +35,12 @@ type PullRequest struct {
 	Comments  int        `json:"comments"`
 	Labels    []string   `json:"labels,omitempty"`
 	IsDraft   bool       `json:"is_draft,omitempty"`
+	// Diffstat and commits are only filled in by EnrichPullRequests
+	Additions      int      `json:"additions,omitempty"`
+	Deletions      int      `json:"deletions,omitempty"`
+	ChangedFiles   int      `json:"changed_files,omitempty"`
+	CommitMessages []string `json:"commit_messages,omitempty"`
+	CommitSHAs     []string `json:"commit_shas,omitempty"`
 }
 
 // Commit represents essential commit information
@@ -48,7 +56,17 @@ type Summary struct {
 	TotalRepositories int       `json:"total_repositories"`
 	TotalPullRequests int       `json:"total_pull_requests"`
 	TotalCommits      int       `json:"total_commits"`
+	TotalReviews      int       `json:"total_reviews"`
 	DateRange         DateRange `json:"date_range"`
+	Period            DateRange `json:"period"`
+}
+
+// Options controls how activity is grouped into a work log
+type Options struct {
+	// Period is the requested reporting window
+	Period DateRange
+	// Location is the timezone all timestamps are converted to. Defaults to UTC.
+	Location *time.Location
 }
 
 // DateRange represents the time period covered
@@ -0,0 +1,53 @@
+package processing
+
+import "time"
+
+// FilterWorkLog returns the part of the work log with activity between start and end (inclusive).
+// A pull request is kept if it was created, updated, merged or closed in the window.
+// Repositories left without activity are dropped and the summary is recomputed.
+func FilterWorkLog(workLog WorkLog, start, end time.Time) WorkLog {
+	repositories := make([]RepositoryActivity, 0, len(workLog.Repositories))
+
+	for _, repo := range workLog.Repositories {
+		filtered := repo
+		filtered.PullRequests = filterPullRequests(repo.PullRequests, start, end)
+		filtered.Reviews = filterPullRequests(repo.Reviews, start, end)
+
+		filtered.Commits = nil
+		for _, commit := range repo.Commits {
+			if inWindow(commit.Date, start, end) {
+				filtered.Commits = append(filtered.Commits, commit)
+			}
+		}
+
+		if len(filtered.PullRequests)+len(filtered.Commits)+len(filtered.Reviews) > 0 {
+			repositories = append(repositories, filtered)
+		}
+	}
+
+	summary := generateSummary(repositories)
+	summary.Period = DateRange{Start: start, End: end}
+
+	return WorkLog{
+		Repositories: repositories,
+		Summary:      summary,
+	}
+}
+
+func filterPullRequests(prs []PullRequest, start, end time.Time) []PullRequest {
+	var filtered []PullRequest
+	for _, pr := range prs {
+		active := inWindow(pr.CreatedAt, start, end) || inWindow(pr.UpdatedAt, start, end) ||
+			(pr.MergedAt != nil && inWindow(*pr.MergedAt, start, end)) ||
+			(pr.ClosedAt != nil && inWindow(*pr.ClosedAt, start, end))
+		if active {
+			filtered = append(filtered, pr)
+		}
+	}
+	return filtered
+}
+
+// inWindow reports whether t falls between start and end (inclusive)
+func inWindow(t, start, end time.Time) bool {
+	return !t.Before(start) && !t.After(end)
+}
@@ -0,0 +1,170 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// DefaultAnthropicBaseURL is used when no base URL is configured
+	DefaultAnthropicBaseURL = "https://api.anthropic.com"
+
+	anthropicVersion = "2023-06-01"
+
+	// max_tokens is required by the Messages API
+	defaultAnthropicMaxTokens = 8192
+
+	// maxContinuations bounds how often a truncated report is continued
+	maxContinuations = 3
+)
+
+// AnthropicProvider generates text with the Anthropic Messages API
+type AnthropicProvider struct {
+	BaseURL    string
+	APIKey     string
+	Model      string
+	HTTPClient *http.Client
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature *float32           `json:"temperature,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Error      *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func NewAnthropicProvider(baseURL, apiKey, model string) *AnthropicProvider {
+	if baseURL == "" {
+		baseURL = DefaultAnthropicBaseURL
+	}
+
+	return &AnthropicProvider{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  apiKey,
+		Model:   model,
+		// Generation is bounded by the caller's context rather than a fixed timeout
+		HTTPClient: &http.Client{},
+	}
+}
+
+// EstimateTokens approximates Claude's tokenizer, which averages closer to 3.5 characters per token
+func (p *AnthropicProvider) EstimateTokens(text string) int {
+	return (len(text)*2 + 6) / 7
+}
+
+// Generate sends the prompts to the Messages API. When the response stops at max_tokens,
+// the partial report is sent back as an assistant prefill so the model continues where it left off.
+// The Messages API has no response schema, so structured output relies on the prompt alone.
+func (p *AnthropicProvider) Generate(ctx context.Context, system, user string, opts GenerateOptions) (string, error) {
+	maxTokens := opts.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultAnthropicMaxTokens
+	}
+
+	messages := []anthropicMessage{{Role: "user", Content: user}}
+	var text string
+
+	for attempt := 0; ; attempt++ {
+		response, err := p.send(ctx, anthropicRequest{
+			Model:       p.Model,
+			System:      system,
+			Messages:    messages,
+			MaxTokens:   maxTokens,
+			Temperature: opts.Temperature,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		for _, block := range response.Content {
+			if block.Type == "text" {
+				text += block.Text
+			}
+		}
+
+		if response.StopReason != "max_tokens" {
+			return text, nil
+		}
+
+		if attempt == maxContinuations {
+			fmt.Printf("Warning: The report is still cut off by max_tokens after %d continuations\n", maxContinuations)
+			return text, nil
+		}
+
+		fmt.Println("Response reached max_tokens, continuing...")
+
+		// The API rejects an assistant prefill ending in whitespace
+		text = strings.TrimRight(text, " \t\n")
+		messages = []anthropicMessage{
+			{Role: "user", Content: user},
+			{Role: "assistant", Content: text},
+		}
+	}
+}
+
+func (p *AnthropicProvider) send(ctx context.Context, request anthropicRequest) (*anthropicResponse, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.BaseURL+"/v1/messages", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.APIKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response anthropicResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("messages request failed with status: %d, body: %s", resp.StatusCode, string(body))
+		}
+		return nil, fmt.Errorf("parsing messages response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if response.Error != nil {
+			return nil, fmt.Errorf("messages request failed with status: %d: %s", resp.StatusCode, response.Error.Message)
+		}
+		return nil, fmt.Errorf("messages request failed with status: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return &response, nil
+}
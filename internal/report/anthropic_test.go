@@ -0,0 +1,186 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAnthropicProviderGenerate(t *testing.T) {
+	var gotPath string
+	var gotHeader http.Header
+	var gotBody map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotHeader = r.Header.Clone()
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &gotBody); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		io.WriteString(w, `{"content":[{"type":"text","text":"# Report"}],"stop_reason":"end_turn"}`)
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(server.URL+"/", "sk-ant-test", "claude-sonnet-4-5")
+	got, err := provider.Generate(context.Background(), "system prompt", "user prompt", GenerateOptions{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got != "# Report" {
+		t.Errorf("Generate() = %q, want %q", got, "# Report")
+	}
+
+	if gotPath != "/v1/messages" {
+		t.Errorf("path = %s, want /v1/messages", gotPath)
+	}
+	if key := gotHeader.Get("x-api-key"); key != "sk-ant-test" {
+		t.Errorf("x-api-key = %q, want %q", key, "sk-ant-test")
+	}
+	if version := gotHeader.Get("anthropic-version"); version != anthropicVersion {
+		t.Errorf("anthropic-version = %q, want %q", version, anthropicVersion)
+	}
+
+	// The system prompt is a top-level field, not a message
+	if gotBody["system"] != "system prompt" {
+		t.Errorf("system = %v, want %q", gotBody["system"], "system prompt")
+	}
+	messages, _ := gotBody["messages"].([]any)
+	if len(messages) != 1 {
+		t.Fatalf("messages = %v, want only the user message", gotBody["messages"])
+	}
+	if message, _ := messages[0].(map[string]any); message["role"] != "user" || message["content"] != "user prompt" {
+		t.Errorf("messages[0] = %v, want the user prompt", message)
+	}
+
+	if gotBody["model"] != "claude-sonnet-4-5" {
+		t.Errorf("model = %v, want claude-sonnet-4-5", gotBody["model"])
+	}
+	if gotBody["max_tokens"] != float64(defaultAnthropicMaxTokens) {
+		t.Errorf("max_tokens = %v, want the default %d", gotBody["max_tokens"], defaultAnthropicMaxTokens)
+	}
+	if _, ok := gotBody["temperature"]; ok {
+		t.Errorf("temperature sent although it was not set")
+	}
+}
+
+func TestAnthropicProviderContinuesAtMaxTokens(t *testing.T) {
+	var requests []anthropicRequest
+	responses := []string{
+		`{"content":[{"type":"text","text":"# Report\n\n## api\n"}],"stop_reason":"max_tokens"}`,
+		`{"content":[{"type":"text","text":"\n* Login (#1)\n"}],"stop_reason":"end_turn"}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		requests = append(requests, request)
+		io.WriteString(w, responses[len(requests)-1])
+	}))
+	defer server.Close()
+
+	got, err := NewAnthropicProvider(server.URL, "key", "claude-sonnet-4-5").Generate(context.Background(), "s", "u", GenerateOptions{MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if want := "# Report\n\n## api\n* Login (#1)\n"; got != want {
+		t.Errorf("Generate() = %q, want %q", got, want)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("made %d requests, want 2", len(requests))
+	}
+	continuation := requests[1]
+	if continuation.System != "s" || continuation.MaxTokens != 100 {
+		t.Errorf("continuation system = %q, max_tokens = %d, want the original settings", continuation.System, continuation.MaxTokens)
+	}
+	// The partial report is sent back as a prefill without its trailing whitespace
+	want := []anthropicMessage{
+		{Role: "user", Content: "u"},
+		{Role: "assistant", Content: "# Report\n\n## api"},
+	}
+	if len(continuation.Messages) != len(want) || continuation.Messages[0] != want[0] || continuation.Messages[1] != want[1] {
+		t.Errorf("continuation messages = %v, want %v", continuation.Messages, want)
+	}
+}
+
+func TestAnthropicProviderStopsContinuing(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		io.WriteString(w, `{"content":[{"type":"text","text":"more "}],"stop_reason":"max_tokens"}`)
+	}))
+	defer server.Close()
+
+	got, err := NewAnthropicProvider(server.URL, "key", "claude-sonnet-4-5").Generate(context.Background(), "s", "u", GenerateOptions{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if requests != maxContinuations+1 {
+		t.Errorf("made %d requests, want %d", requests, maxContinuations+1)
+	}
+	if want := "moremoremoremore "; got != want {
+		t.Errorf("Generate() = %q, want %q", got, want)
+	}
+}
+
+func TestAnthropicProviderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "API error message",
+			status:  http.StatusBadRequest,
+			body:    `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: must be positive"}}`,
+			wantErr: "status: 400: max_tokens: must be positive",
+		},
+		{
+			name:    "overloaded",
+			status:  529,
+			body:    `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			wantErr: "status: 529: Overloaded",
+		},
+		{
+			name:    "non-JSON error body",
+			status:  http.StatusBadGateway,
+			body:    "upstream unavailable",
+			wantErr: "status: 502, body: upstream unavailable",
+		},
+		{
+			name:    "JSON error without message",
+			status:  http.StatusTooManyRequests,
+			body:    `{}`,
+			wantErr: "status: 429, body: {}",
+		},
+		{
+			name:    "malformed success body",
+			status:  http.StatusOK,
+			body:    "not json",
+			wantErr: "parsing messages response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			_, err := NewAnthropicProvider(server.URL, "key", "claude-sonnet-4-5").Generate(context.Background(), "s", "u", GenerateOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
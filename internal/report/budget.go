@@ -0,0 +1,147 @@
+package report
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"git-log/internal/processing"
+)
+
+// TokenEstimator is implemented by providers whose tokenizer differs noticeably
+// from the four characters per token EstimateTokens assumes
+type TokenEstimator interface {
+	EstimateTokens(text string) int
+}
+
+var (
+	htmlCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	imagePattern       = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)|<img[^>]*>`)
+	blankLinesPattern  = regexp.MustCompile(`\n\s*\n(\s*\n)+`)
+)
+
+// estimateTokens counts tokens the way the provider does, as far as it tells us
+func estimateTokens(provider Provider, text string) int {
+	if estimator, ok := provider.(TokenEstimator); ok {
+		return estimator.EstimateTokens(text)
+	}
+	return EstimateTokens(text)
+}
+
+// promptBudget is how many tokens a prompt may use, or zero for no limit. It is the smaller of
+// the configured budget and what a context limited provider has left after the response.
+func promptBudget(provider Provider, tokenBudget, maxTokens int) int {
+	budget := tokenBudget
+
+	if limited, ok := provider.(ContextLimited); ok {
+		window := limited.ContextWindow()
+		outputTokens := maxTokens
+		if outputTokens == 0 {
+			outputTokens = window / 4
+		}
+		if available := window - outputTokens; budget == 0 || available < budget {
+			budget = available
+		}
+	}
+
+	return budget
+}
+
+// CondenseWorkLog drops the parts of PR descriptions that cost tokens without describing
+// the work: template comments, images and runs of blank lines. Bodies are then cut to
+// bodyLimit bytes; zero leaves the length alone. The input is left untouched.
+func CondenseWorkLog(workLog processing.WorkLog, bodyLimit int) processing.WorkLog {
+	condensed := workLog
+	condensed.Repositories = make([]processing.RepositoryActivity, len(workLog.Repositories))
+
+	for i, repo := range workLog.Repositories {
+		repo.PullRequests = condenseBodies(repo.PullRequests, bodyLimit)
+		repo.Reviews = condenseBodies(repo.Reviews, bodyLimit)
+		condensed.Repositories[i] = repo
+	}
+
+	return condensed
+}
+
+func condenseBodies(prs []processing.PullRequest, bodyLimit int) []processing.PullRequest {
+	if prs == nil {
+		return nil
+	}
+
+	condensed := make([]processing.PullRequest, len(prs))
+	for i, pr := range prs {
+		body := htmlCommentPattern.ReplaceAllString(pr.Body, "")
+		body = imagePattern.ReplaceAllString(body, "")
+		body = blankLinesPattern.ReplaceAllString(body, "\n\n")
+		body = strings.TrimSpace(body)
+		if bodyLimit > 0 {
+			body = truncate(body, bodyLimit)
+		}
+		pr.Body = body
+		condensed[i] = pr
+	}
+	return condensed
+}
+
+// batchRepositories splits the work log into batches whose repositories, together with
+// the report sections they touch, fit in budget tokens. A repository too large for a
+// batch of its own gets one anyway and is trimmed when its prompt is built.
+func batchRepositories(provider Provider, workLog processing.WorkLog, document *Document, budget int) ([]processing.WorkLog, error) {
+	var batches []processing.WorkLog
+	var current []processing.RepositoryActivity
+	used := 0
+
+	for _, repo := range workLog.Repositories {
+		repoBytes, err := json.Marshal(repo)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens := estimateTokens(provider, string(repoBytes))
+		if document != nil {
+			// The excerpt holds the repository's section and its work in progress entries
+			for _, section := range document.Excerpt([]string{repo.Name}).Sections {
+				tokens += estimateTokens(provider, strings.Join(section.lines(), "\n"))
+			}
+		}
+
+		if len(current) > 0 && used+tokens > budget {
+			batches = append(batches, batchWorkLog(workLog, current))
+			current, used = nil, 0
+		}
+		current = append(current, repo)
+		used += tokens
+	}
+
+	if len(current) > 0 {
+		batches = append(batches, batchWorkLog(workLog, current))
+	}
+	return batches, nil
+}
+
+// batchWorkLog builds a work log for a subset of repositories with matching totals
+func batchWorkLog(workLog processing.WorkLog, repos []processing.RepositoryActivity) processing.WorkLog {
+	batch := processing.WorkLog{Repositories: repos, Summary: workLog.Summary}
+	batch.Summary.TotalRepositories = len(repos)
+	batch.Summary.TotalPullRequests = 0
+	batch.Summary.TotalCommits = 0
+	batch.Summary.TotalReviews = 0
+
+	for _, repo := range repos {
+		batch.Summary.TotalPullRequests += len(repo.PullRequests)
+		batch.Summary.TotalCommits += len(repo.Commits)
+		batch.Summary.TotalReviews += len(repo.Reviews)
+	}
+	return batch
+}
+
+// InputTokens estimates the size of the prompt as the provider counts it
+func (p Prompt) InputTokens(provider Provider) int {
+	return estimateTokens(provider, p.System) + estimateTokens(provider, p.User)
+}
+
+// OutputTokens guesses the size of the response: the report sections that were sent,
+// which come back updated, plus a few hundred tokens for every repository in the prompt
+func (p Prompt) OutputTokens(provider Provider) int {
+	return estimateTokens(provider, p.Report) + 300*len(p.WorkLog.Repositories)
+}
@@ -0,0 +1,164 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is how many unchanged lines surround each change in a unified diff
+const diffContext = 3
+
+// diffOp is a single line of an edit script
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+	// oldLine and newLine are the 1-based positions before the line is applied
+	oldLine, newLine int
+}
+
+// UnifiedDiff returns the changes from previous to updated in unified diff format,
+// or an empty string if they are the same
+func UnifiedDiff(name, previous, updated string) string {
+	if previous == updated {
+		return ""
+	}
+
+	ops := diffLines(splitLines(previous), splitLines(updated))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+
+	// Changes closer than twice the context share a hunk
+	var changes []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	for first := 0; first < len(changes); {
+		last := first
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*diffContext {
+			last++
+		}
+
+		from := max(changes[first]-diffContext, 0)
+		to := min(changes[last]+diffContext+1, len(ops))
+		writeHunk(&b, ops[from:to])
+		first = last + 1
+	}
+
+	return b.String()
+}
+
+// DiffSummary counts the lines a diff adds and removes. Only the "---"/"+++" file header
+// before the first hunk is skipped, since a removed "-- x" line is written as "--- x" too.
+func DiffSummary(diff string) (added, removed int) {
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
+
+func writeHunk(b *strings.Builder, ops []diffOp) {
+	oldStart, newStart := ops[0].oldLine, ops[0].newLine
+	oldCount, newCount := 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+
+	// An empty side of a hunk starts at the line before it, as diff -u does
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, op := range ops {
+		fmt.Fprintf(b, "%c%s\n", op.kind, op.line)
+	}
+}
+
+// diffLines computes a line edit script from the longest common subsequence. The lines
+// shared at the start and end are matched first, so the quadratic table only covers the
+// region that changed, which for a report updated in place is a few sections at most.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b)-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{kind: ' ', line: a[i], oldLine: i + 1, newLine: i + 1})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix)...)
+	for k := suffix; k > 0; k-- {
+		i, j := len(a)-k, len(b)-k
+		ops = append(ops, diffOp{kind: ' ', line: a[i], oldLine: i + 1, newLine: j + 1})
+	}
+	return ops
+}
+
+// diffMiddle diffs the changed region of two texts, which starts offset lines into both
+func diffMiddle(a, b []string, offset int) []diffOp {
+	// lcs[i*width+j] is the LCS length of a[i:] and b[j:]
+	width := len(b) + 1
+	lcs := make([]int, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		oldLine, newLine := offset+i+1, offset+j+1
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', line: a[i], oldLine: oldLine, newLine: newLine})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[(i+1)*width+j] >= lcs[i*width+j+1]):
+			// Removals come before additions, as diff -u prints them
+			ops = append(ops, diffOp{kind: '-', line: a[i], oldLine: oldLine, newLine: newLine})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', line: b[j], oldLine: oldLine, newLine: newLine})
+			j++
+		}
+	}
+	return ops
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,126 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	numbered := func(lines ...string) string {
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	tests := []struct {
+		name              string
+		previous, updated string
+		want              string
+	}{
+		{
+			name:     "unchanged",
+			previous: "a\nb\n",
+			updated:  "a\nb\n",
+			want:     "",
+		},
+		{
+			name:     "changed line",
+			previous: "a\nb\nc\n",
+			updated:  "a\nB\nc\n",
+			want:     "--- a/log.md\n+++ b/log.md\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "new file",
+			previous: "",
+			updated:  "x\n",
+			want:     "--- a/log.md\n+++ b/log.md\n@@ -0,0 +1,1 @@\n+x\n",
+		},
+		{
+			name:     "removed lines at the end",
+			previous: "a\nb\nc\n",
+			updated:  "a\n",
+			want:     "--- a/log.md\n+++ b/log.md\n@@ -1,3 +1,1 @@\n a\n-b\n-c\n",
+		},
+		{
+			name:     "distant changes get separate hunks",
+			previous: numbered("1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"),
+			updated:  numbered("1", "2a", "3", "4", "5", "6", "7", "8", "9", "10", "11a", "12"),
+			want: "--- a/log.md\n+++ b/log.md\n" +
+				"@@ -1,5 +1,5 @@\n 1\n-2\n+2a\n 3\n 4\n 5\n" +
+				"@@ -8,5 +8,5 @@\n 8\n 9\n 10\n-11\n+11a\n 12\n",
+		},
+		{
+			name:     "repeated lines around the change",
+			previous: "x\nx\nx\n",
+			updated:  "x\nx\nx\nx\n",
+			want:     "--- a/log.md\n+++ b/log.md\n@@ -1,3 +1,4 @@\n x\n x\n x\n+x\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnifiedDiff("log.md", tt.previous, tt.updated); got != tt.want {
+				t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffLargeReport(t *testing.T) {
+	// A long report with one change in the middle only diffs the changed region
+	lines := make([]string, 20000)
+	for i := range lines {
+		lines[i] = strings.Repeat("x", i%50)
+	}
+	previous := strings.Join(lines, "\n") + "\n"
+	lines[10000] = "changed"
+	updated := strings.Join(lines, "\n") + "\n"
+
+	added, removed := DiffSummary(UnifiedDiff("log.md", previous, updated))
+	if added != 1 || removed != 1 {
+		t.Errorf("DiffSummary() = +%d -%d, want +1 -1", added, removed)
+	}
+}
+
+func TestDiffSummary(t *testing.T) {
+	tests := []struct {
+		name              string
+		previous, updated string
+		wantAdded         int
+		wantRemoved       int
+	}{
+		{
+			name:     "unchanged",
+			previous: "a\n",
+			updated:  "a\n",
+		},
+		{
+			name:        "changed line",
+			previous:    "a\nb\nc\n",
+			updated:     "a\nB\nc\n",
+			wantAdded:   1,
+			wantRemoved: 1,
+		},
+		{
+			name:        "content lines that look like file headers",
+			previous:    "# T\n-- x\n+ y\n",
+			updated:     "# T\n++ y\n",
+			wantAdded:   1,
+			wantRemoved: 2,
+		},
+		{
+			name:      "added lines",
+			previous:  "a\n",
+			updated:   "a\nb\nc\n",
+			wantAdded: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := UnifiedDiff("log.md", tt.previous, tt.updated)
+			added, removed := DiffSummary(diff)
+			if added != tt.wantAdded || removed != tt.wantRemoved {
+				t.Errorf("DiffSummary() = +%d -%d, want +%d -%d\n%s", added, removed, tt.wantAdded, tt.wantRemoved, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package report
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// GeminiProvider generates text with the Google Gemini API
+type GeminiProvider struct {
+	client *genai.Client
+	model  string
+}
+
+func NewGeminiProvider(ctx context.Context, apiKey, model string) (*GeminiProvider, error) {
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:  apiKey,
+		Backend: genai.BackendGeminiAPI,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating Gemini client: %w", err)
+	}
+
+	return &GeminiProvider{
+		client: client,
+		model:  model,
+	}, nil
+}
+
+func (p *GeminiProvider) Generate(ctx context.Context, system, user string, opts GenerateOptions) (string, error) {
+	config := &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText(system, genai.RoleUser),
+		Temperature:       opts.Temperature,
+		MaxOutputTokens:   int32(opts.MaxTokens),
+	}
+	if opts.ResponseSchema != nil {
+		config.ResponseMIMEType = "application/json"
+		config.ResponseJsonSchema = opts.ResponseSchema
+	}
+
+	result, err := p.client.Models.GenerateContent(ctx, p.model, genai.Text(user), config)
+	if err != nil {
+		return "", fmt.Errorf("generating content: %w", err)
+	}
+
+	return result.Text(), nil
+}
@@ -4,82 +4,314 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
+	"strings"
 
 	"git-log/internal/processing"
-	"google.golang.org/genai"
 )
 
-func GenerateReport(model string, workLog processing.WorkLog, reportPath string) (string, error) {
+// Request describes a single report generation
+type Request struct {
+	WorkLog    processing.WorkLog
+	ReportPath string
+	Username   string
+	Audience   string
+	Prompts    Prompts
+	Options    GenerateOptions
+	// Regenerate writes the report from scratch instead of merging into the existing one
+	Regenerate bool
+	// SectionMerge sends only the report sections the work log touches and splices the update back in
+	SectionMerge bool
+	// Structured asks the model for a StructuredReport and renders the Markdown locally
+	Structured bool
+	// CheckIntegrity compares the report's PR references against the work log and the previous report
+	CheckIntegrity bool
+	// IntegrityRetries is how often the model is re-prompted with the problems the check found
+	IntegrityRetries int
+	// TokenBudget caps the tokens of a single prompt. Zero leaves it to the provider's context window.
+	TokenBudget int
+	// BodyLimit cuts PR descriptions to this many bytes. Zero keeps them whole.
+	BodyLimit int
+}
+
+// Prompt is a fully rendered generation request
+type Prompt struct {
+	System  string
+	User    string
+	Options GenerateOptions
+	// WorkLog is the part of the work log this prompt covers, before trimming
+	WorkLog processing.WorkLog
+	// Report is the existing report, or the excerpt of it, included in the user prompt
+	Report string
 
-	// Use the embedded system prompt
-	systemContent := genai.NewContentFromText(SystemPrompt, genai.RoleUser)
+	// document and excerpt are set when only part of the report was sent
+	document, excerpt *Document
+	repoNames         []string
+}
 
-	// We check if the file exists. If not (e.g., first run), we use an empty string.
-	var reportString string
-	reportBytes, err := os.ReadFile(reportPath)
+// GenerateReport merges the work log into the existing report at req.ReportPath using the provider
+func GenerateReport(ctx context.Context, provider Provider, req Request) (string, error) {
+	reportString, err := readReport(req)
 	if err != nil {
-		if os.IsNotExist(err) {
-			reportString = ""
-		} else {
-			log.Fatalf("Failed to read report file: %v", err)
+		return "", err
+	}
+
+	batches, budget, err := planBatches(provider, req, reportString)
+	if err != nil {
+		return "", err
+	}
+	if len(batches) > 1 {
+		fmt.Printf("The work log exceeds the %d token budget, generating in %d batches\n", budget, len(batches))
+	}
+
+	result := reportString
+	for i, batch := range batches {
+		if len(batches) > 1 {
+			fmt.Printf("Generating batch %d of %d (%d repositories)...\n", i+1, len(batches), len(batch.Repositories))
+		}
+		result, err = generatePass(ctx, provider, req, result, batch, budget)
+		if err != nil {
 			return "", err
 		}
-	} else {
-		reportString = string(reportBytes)
 	}
 
-	logBytes, err := json.Marshal(workLog)
+	// The result will be the complete, updated Markdown report
+	fmt.Println(result)
+
+	return result, nil
+}
+
+// PreparePrompts renders the prompts GenerateReport would send, without calling the provider.
+// Batches after the first are rendered against the current report, since the real run would
+// build on the output of the batches before them.
+func PreparePrompts(provider Provider, req Request) ([]Prompt, error) {
+	reportString, err := readReport(req)
 	if err != nil {
-		fmt.Printf("Error converting workLog to JSON: %v\n", err)
-		return "", err
+		return nil, err
+	}
+
+	batches, budget, err := planBatches(provider, req, reportString)
+	if err != nil {
+		return nil, err
+	}
+
+	prompts := make([]Prompt, 0, len(batches))
+	for _, batch := range batches {
+		prompt, err := buildPrompt(provider, req, reportString, batch, budget, "")
+		if err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, prompt)
 	}
+	return prompts, nil
+}
 
-	logString := string(logBytes)
+// readReport returns the existing report without its marker, or an empty string on the
+// first run and when the report is regenerated from scratch
+func readReport(req Request) (string, error) {
+	reportBytes, err := os.ReadFile(req.ReportPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading report file: %w", err)
+	}
+	if req.Regenerate {
+		return "", nil
+	}
+	return StripMarker(string(reportBytes)), nil
+}
 
-	userPromptTemplate := `Here is the existing accomplishment report and the work log for the past month.
+// planBatches condenses the work log and splits it into batches that fit the prompt budget.
+// A work log too large for one prompt is merged a few repositories at a time. Each batch
+// only touches its own sections, so this relies on the section layout of a merged report.
+func planBatches(provider Provider, req Request, reportString string) ([]processing.WorkLog, int, error) {
+	workLog := CondenseWorkLog(req.WorkLog, req.BodyLimit)
+	budget := promptBudget(provider, req.TokenBudget, req.Options.MaxTokens)
 
-Please update and merge the report according to your system instructions.
+	if budget == 0 || !req.SectionMerge {
+		return []processing.WorkLog{workLog}, budget, nil
+	}
 
-report.md:
+	fixed, err := fixedPromptTokens(provider, req)
+	if err != nil {
+		return nil, 0, err
+	}
 
-%s
+	var document *Document
+	if reportString != "" {
+		document = ParseDocument(reportString)
+	}
+	batches, err := batchRepositories(provider, workLog, document, budget-fixed)
+	if err != nil {
+		return nil, 0, fmt.Errorf("batching work log: %w", err)
+	}
+	return batches, budget, nil
+}
 
-work_log.json:
+// buildPrompt renders the prompts merging one work log into the report. Any repair instructions
+// are appended to the user prompt and counted against the budget.
+func buildPrompt(provider Provider, req Request, reportString string, workLog processing.WorkLog, budget int, repair string) (Prompt, error) {
+	prompt := Prompt{Options: req.Options, WorkLog: workLog}
 
-%s
-`
-	// Use fmt.Sprintf to "paste" the file contents into the template
-	finalUserPromptString := fmt.Sprintf(userPromptTemplate, reportString, logString)
+	period := workLog.Summary.Period
+	data := PromptData{
+		Username:    req.Username,
+		PeriodStart: period.Start.Format("Jan 2, 2006"),
+		PeriodEnd:   period.End.Format("Jan 2, 2006"),
+		RepoCount:   workLog.Summary.TotalRepositories,
+		Audience:    req.Audience,
+	}
 
-	// --- 4. Set up the API Client and Config ---
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, nil)
+	systemPrompt, err := req.Prompts.RenderSystem(data)
+	if err != nil {
+		return prompt, err
+	}
+
+	// Only the sections the work log can change are sent; the rest of the report is kept as is
+	data.Report = reportString
+	if req.SectionMerge && reportString != "" {
+		for _, repo := range workLog.Repositories {
+			prompt.repoNames = append(prompt.repoNames, repo.Name)
+		}
+		prompt.document = ParseDocument(reportString)
+		prompt.excerpt = prompt.document.Excerpt(prompt.repoNames)
+		systemPrompt += SectionMergeInstructions
+
+		data.Report = ""
+		if len(prompt.excerpt.Sections) > 0 {
+			data.Report = prompt.excerpt.String()
+		}
+	}
+
+	if req.Structured {
+		systemPrompt += StructuredOutputInstructions
+		prompt.Options.ResponseSchema = ReportSchema
+	}
+
+	// Whatever is left of the budget goes to the work log, dropping detail until it fits
+	promptLog := workLog
+	if budget > 0 {
+		available := budget - estimateTokens(provider, systemPrompt) -
+			estimateTokens(provider, req.Prompts.User) - estimateTokens(provider, data.Report) -
+			estimateTokens(provider, repair)
+
+		trimmed, fits, err := TrimWorkLog(provider, workLog, available)
+		if err != nil {
+			return prompt, fmt.Errorf("trimming work log: %w", err)
+		}
+		if !fits {
+			fmt.Printf("Warning: The work log still exceeds the %d token budget after trimming\n", budget)
+		}
+		promptLog = trimmed
+	}
+
+	logBytes, err := json.Marshal(promptLog)
+	if err != nil {
+		fmt.Printf("Error converting workLog to JSON: %v\n", err)
+		return prompt, err
+	}
+	data.WorkLog = string(logBytes)
+
+	userPrompt, err := req.Prompts.RenderUser(data)
+	if err != nil {
+		return prompt, err
+	}
+
+	prompt.System = systemPrompt
+	prompt.User = userPrompt + repair
+	prompt.Report = data.Report
+	return prompt, nil
+}
+
+// generatePass merges one work log into the report, checking and repairing its integrity
+func generatePass(ctx context.Context, provider Provider, req Request, reportString string, workLog processing.WorkLog, budget int) (string, error) {
+	// produce builds the prompt, runs a generation and splices a section update back into the full report
+	produce := func(repair string) (attempt, result string, err error) {
+		prompt, err := buildPrompt(provider, req, reportString, workLog, budget, repair)
+		if err != nil {
+			return "", "", err
+		}
+		attempt, err = generateMarkdown(ctx, provider, prompt.System, prompt.User, prompt.Options, req.Structured)
+		if err != nil || prompt.document == nil {
+			return attempt, attempt, err
+		}
+		spliced, ignored := prompt.document.Splice(ParseDocument(attempt), prompt.excerpt, prompt.repoNames)
+		if len(ignored) > 0 {
+			fmt.Printf("Warning: Ignored sections the model was not asked to update: %s\n", strings.Join(ignored, ", "))
+		}
+		return attempt, spliced.String(), nil
+	}
+
+	attempt, result, err := produce("")
 	if err != nil {
-		log.Fatalf("Failed to create new client: %v", err)
 		return "", err
 	}
 
-	config := &genai.GenerateContentConfig{
-		SystemInstruction: systemContent,
+	for retry := 0; req.CheckIntegrity; retry++ {
+		integrity := CheckIntegrity(workLog, reportString, result)
+		if integrity.OK() {
+			break
+		}
+
+		fmt.Printf("Warning: The report failed the integrity check:\n%s\n", integrity)
+		if !integrity.NeedsRepair() || retry == req.IntegrityRetries {
+			break
+		}
+
+		fmt.Println("Re-prompting with the problems found...")
+		attempt, result, err = produce(repairPrompt(attempt, integrity.Repairable()))
+		if err != nil {
+			return "", err
+		}
 	}
 
-	userMessage := genai.Text(finalUserPromptString)
+	return result, nil
+}
+
+// fixedPromptTokens estimates the prompt tokens that don't depend on the work log or the report
+func fixedPromptTokens(provider Provider, req Request) (int, error) {
+	systemPrompt, err := req.Prompts.RenderSystem(PromptData{Username: req.Username, Audience: req.Audience})
+	if err != nil {
+		return 0, err
+	}
+	systemPrompt += SectionMergeInstructions
+	if req.Structured {
+		systemPrompt += StructuredOutputInstructions
+	}
+	return estimateTokens(provider, systemPrompt) + estimateTokens(provider, req.Prompts.User), nil
+}
 
-	result, err := client.Models.GenerateContent(
-		ctx,
-		model,
-		userMessage,
-		config,
-	)
+// generateMarkdown runs a single generation, rendering structured output to Markdown
+func generateMarkdown(ctx context.Context, provider Provider, system, user string, opts GenerateOptions, structured bool) (string, error) {
+	result, err := provider.Generate(ctx, system, user, opts)
 	if err != nil {
-		log.Fatalf("Failed to generate content: %v", err)
 		return "", err
 	}
 
-	// The result.Text() will be the complete, updated Markdown report
-	fmt.Println(result.Text())
+	if !structured {
+		return result, nil
+	}
 
-	return result.Text(), nil
+	report, err := ParseStructuredReport(result)
+	if err != nil {
+		return "", err
+	}
+	return report.Markdown(), nil
+}
+
+// repairPrompt asks the model to fix the problems found in its previous attempt
+func repairPrompt(previousAttempt string, integrity IntegrityReport) string {
+	return fmt.Sprintf(`
+
+A previous attempt at this task produced the report below, but it has these problems:
+
+%s
+
+Produce the report again, following your system instructions, and fix these problems: only reference pull requests that exist in the work log or the existing report, and keep every entry of the existing report.
+
+previous_attempt.md:
+
+%s
+`, integrity, previousAttempt)
 }
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GuardLimits is how much content a regenerated report may lose before it is rejected
+type GuardLimits struct {
+	// MaxShrink is the largest tolerated fraction of the previous report's size that may disappear
+	MaxShrink float64
+	// MaxRemoved is the largest tolerated number of removed PR references. A removed section
+	// heading is always rejected, since the report's sections must be preserved.
+	MaxRemoved int
+}
+
+// ContentLoss describes what a new report dropped compared to the previous one
+type ContentLoss struct {
+	// Shrink is the fraction of the previous report's size that disappeared, zero if it grew
+	Shrink            float64
+	RemovedHeadings   []string
+	RemovedReferences []Reference
+}
+
+// CompareReports measures what generated lost compared to previous
+func CompareReports(previous, generated string) ContentLoss {
+	var loss ContentLoss
+
+	previousSize := len(strings.TrimSpace(previous))
+	generatedSize := len(strings.TrimSpace(generated))
+	if previousSize > 0 && generatedSize < previousSize {
+		loss.Shrink = float64(previousSize-generatedSize) / float64(previousSize)
+	}
+
+	generatedHeadings := make(map[string]bool)
+	for _, heading := range headings(generated) {
+		generatedHeadings[strings.ToLower(heading)] = true
+	}
+	for _, heading := range headings(previous) {
+		if !generatedHeadings[strings.ToLower(heading)] {
+			loss.RemovedHeadings = append(loss.RemovedHeadings, heading)
+		}
+	}
+
+	generatedRefs := ExtractReferences(generated, nil)
+	for ref := range ExtractReferences(previous, nil) {
+		if !generatedRefs.Contains(ref) {
+			loss.RemovedReferences = append(loss.RemovedReferences, ref)
+		}
+	}
+	sortReferences(loss.RemovedReferences)
+
+	return loss
+}
+
+// Exceeds reports whether the loss is beyond the limits
+func (l ContentLoss) Exceeds(limits GuardLimits) bool {
+	return l.Shrink > limits.MaxShrink ||
+		len(l.RemovedHeadings) > 0 ||
+		len(l.RemovedReferences) > limits.MaxRemoved
+}
+
+func (l ContentLoss) String() string {
+	lines := []string{fmt.Sprintf("Report shrank by %.0f%%", l.Shrink*100)}
+	if len(l.RemovedHeadings) > 0 {
+		lines = append(lines, "Removed sections: "+strings.Join(l.RemovedHeadings, ", "))
+	}
+	if len(l.RemovedReferences) > 0 {
+		lines = append(lines, "Removed PR references: "+joinReferences(l.RemovedReferences))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// headings returns the repository and workstream headings of a report. The work in
+// progress section is left out because it is expected to empty out as work is promoted.
+func headings(markdown string) []string {
+	var result []string
+	for _, line := range strings.Split(markdown, "\n") {
+		if !strings.HasPrefix(line, "## ") && !strings.HasPrefix(line, "### ") {
+			continue
+		}
+		heading := strings.TrimSpace(strings.TrimLeft(line, "#"))
+		if strings.Contains(strings.ToLower(heading), "work in progress") {
+			continue
+		}
+		result = append(result, heading)
+	}
+	return result
+}
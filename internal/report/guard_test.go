@@ -0,0 +1,92 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContentLossExceeds(t *testing.T) {
+	previous := "# Log\n\n## api\n\n### Auth\n* Login (#1)\n* Logout (#2)\n\n## 🚧 Work in Progress\n\n* api: Rate limiting (#5)\n"
+	limits := GuardLimits{MaxShrink: 0.2, MaxRemoved: 0}
+
+	tests := []struct {
+		name      string
+		generated string
+		want      bool
+	}{
+		{
+			name:      "unchanged",
+			generated: previous,
+			want:      false,
+		},
+		{
+			name:      "grown",
+			generated: previous + "\n## web\n\n### UI\n* Page (#3)\n",
+			want:      false,
+		},
+		{
+			name:      "removed heading",
+			generated: strings.Replace(previous, "### Auth", "### Authentication", 1),
+			want:      true,
+		},
+		{
+			name:      "removed work in progress section",
+			generated: "# Log\n\n## api\n\n### Auth\n* Login (#1)\n* Logout (#2)\n\n### Limits\n* Rate limiting (#5)\n",
+			want:      false,
+		},
+		{
+			name:      "removed PR reference",
+			generated: strings.Replace(previous, " (#2)", "", 1),
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loss := CompareReports(previous, tt.generated)
+			if got := loss.Exceeds(limits); got != tt.want {
+				t.Errorf("Exceeds() = %v, want %v (loss: %s)", got, tt.want, loss)
+			}
+		})
+	}
+}
+
+func TestContentLossShrinkThreshold(t *testing.T) {
+	previous := strings.Repeat("x", 100)
+
+	tests := []struct {
+		name      string
+		generated string
+		want      bool
+	}{
+		{name: "below the threshold", generated: strings.Repeat("x", 90), want: false},
+		{name: "at the threshold", generated: strings.Repeat("x", 80), want: false},
+		{name: "past the threshold", generated: strings.Repeat("x", 79), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loss := CompareReports(previous, tt.generated)
+			if got := loss.Exceeds(GuardLimits{MaxShrink: 0.2}); got != tt.want {
+				t.Errorf("Exceeds() = %v with shrink %.2f, want %v", got, loss.Shrink, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareReportsListsRemovedContent(t *testing.T) {
+	previous := "## api\n\n### Auth\n* Login (#1)\n\n### Limits\n* Rate limiting (#5)\n"
+	generated := "## api\n\n### Auth\n* Login (#1)\n"
+
+	loss := CompareReports(previous, generated)
+	if want := []string{"Limits"}; !reflect.DeepEqual(loss.RemovedHeadings, want) {
+		t.Errorf("RemovedHeadings = %v, want %v", loss.RemovedHeadings, want)
+	}
+	if want := []Reference{{Repository: "api", Number: 5}}; !reflect.DeepEqual(loss.RemovedReferences, want) {
+		t.Errorf("RemovedReferences = %v, want %v", loss.RemovedReferences, want)
+	}
+	if !loss.Exceeds(GuardLimits{MaxShrink: 1, MaxRemoved: 1}) {
+		t.Error("Exceeds() = false, want true for a removed heading within the reference limit")
+	}
+}
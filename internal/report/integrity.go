@@ -0,0 +1,220 @@
+package report
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git-log/internal/processing"
+)
+
+// referencePattern matches "#123" and "owner/repo#123" where they start a word, so "C#1" and
+// "step#2" aren't taken for references. The character before the reference is part of the match.
+var referencePattern = regexp.MustCompile(`(?:^|[^\w./#-])(?:([\w.-]+/[\w.-]+))?#(\d+)\b`)
+
+// wipBulletPattern matches the "* repo-name: summary" bullets of the work in progress section
+var wipBulletPattern = regexp.MustCompile(`^\s*[*-]\s+\[?([\w.-]+(?:/[\w.-]+)?)\]?:`)
+
+// Reference is a pull request reference attributed to a repository.
+// Repository is empty when the report doesn't make it clear which repository is meant.
+type Reference struct {
+	Repository string
+	Number     int
+}
+
+func (r Reference) String() string {
+	if r.Repository == "" {
+		return fmt.Sprintf("#%d", r.Number)
+	}
+	return fmt.Sprintf("%s#%d", r.Repository, r.Number)
+}
+
+// References is a set of pull request references
+type References map[Reference]bool
+
+// Contains reports whether the set has ref. A reference without a repository
+// matches any repository with the same number, and vice versa.
+func (refs References) Contains(ref Reference) bool {
+	if refs[ref] || refs[Reference{Number: ref.Number}] {
+		return true
+	}
+	if ref.Repository == "" {
+		for other := range refs {
+			if other.Number == ref.Number {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IntegrityReport lists pull request references that don't line up between the work log,
+// the previous report and the generated report
+type IntegrityReport struct {
+	// Missing PRs are in the work log but not mentioned in the generated report. The prompt lets
+	// the model leave out trivial work, so these are only warned about and never repaired.
+	Missing []Reference
+	// Hallucinated references appear in the generated report but in neither the work log nor the previous report
+	Hallucinated []Reference
+	// Deleted references were in the previous report but were dropped from the generated one
+	Deleted []Reference
+}
+
+// OK reports whether no problems were found
+func (r IntegrityReport) OK() bool {
+	return len(r.Missing) == 0 && !r.NeedsRepair()
+}
+
+// NeedsRepair reports whether the generated report invented or dropped references
+func (r IntegrityReport) NeedsRepair() bool {
+	return len(r.Hallucinated) > 0 || len(r.Deleted) > 0
+}
+
+// Repairable returns the problems a repair pass should fix, leaving out missing PRs
+func (r IntegrityReport) Repairable() IntegrityReport {
+	return IntegrityReport{Hallucinated: r.Hallucinated, Deleted: r.Deleted}
+}
+
+func (r IntegrityReport) String() string {
+	var lines []string
+	if len(r.Missing) > 0 {
+		lines = append(lines, "Pull requests from the work log that are not mentioned: "+joinReferences(r.Missing))
+	}
+	if len(r.Hallucinated) > 0 {
+		lines = append(lines, "Pull request references that don't exist in the work log or the previous report: "+joinReferences(r.Hallucinated))
+	}
+	if len(r.Deleted) > 0 {
+		lines = append(lines, "Pull request references from the previous report that were removed: "+joinReferences(r.Deleted))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckIntegrity compares the PR references of the generated report against the work log and the previous report
+func CheckIntegrity(workLog processing.WorkLog, previous, generated string) IntegrityReport {
+	repoNames := make([]string, 0, len(workLog.Repositories))
+	known := make(References)
+	for _, repo := range workLog.Repositories {
+		repoNames = append(repoNames, repo.Name)
+		for _, pr := range repo.PullRequests {
+			known[Reference{Repository: repo.Name, Number: pr.Number}] = true
+		}
+		for _, pr := range repo.Reviews {
+			known[Reference{Repository: repo.Name, Number: pr.Number}] = true
+		}
+	}
+
+	previousRefs := ExtractReferences(previous, repoNames)
+	generatedRefs := ExtractReferences(generated, repoNames)
+
+	var result IntegrityReport
+
+	for _, repo := range workLog.Repositories {
+		for _, pr := range repo.PullRequests {
+			ref := Reference{Repository: repo.Name, Number: pr.Number}
+			if !generatedRefs.Contains(ref) {
+				result.Missing = append(result.Missing, ref)
+			}
+		}
+	}
+
+	for ref := range generatedRefs {
+		if !known.Contains(ref) && !previousRefs.Contains(ref) {
+			result.Hallucinated = append(result.Hallucinated, ref)
+		}
+	}
+
+	for ref := range previousRefs {
+		if !generatedRefs.Contains(ref) {
+			result.Deleted = append(result.Deleted, ref)
+		}
+	}
+
+	sortReferences(result.Missing)
+	sortReferences(result.Hallucinated)
+	sortReferences(result.Deleted)
+
+	return result
+}
+
+// ExtractReferences finds every PR reference in a Markdown report. References are attributed
+// to the "## repository" section they appear in, to the "repo:" prefix of a work in progress
+// bullet, or to an explicit "owner/repo#123" prefix. repoNames are used to recognize section headings.
+func ExtractReferences(markdown string, repoNames []string) References {
+	refs := make(References)
+	section := ""
+	inWIP := false
+
+	for _, line := range strings.Split(markdown, "\n") {
+		switch {
+		case strings.HasPrefix(line, "## "):
+			heading := strings.TrimSpace(strings.TrimPrefix(line, "## "))
+			inWIP = strings.Contains(strings.ToLower(heading), "work in progress")
+			section = ""
+			if !inWIP {
+				section = matchRepository(heading, repoNames)
+			}
+		case strings.HasPrefix(line, "# "):
+			section, inWIP = "", false
+		}
+
+		lineRepo := section
+		if inWIP {
+			lineRepo = ""
+			if m := wipBulletPattern.FindStringSubmatch(line); m != nil {
+				lineRepo = matchRepository(m[1], repoNames)
+			}
+		}
+
+		for _, m := range referencePattern.FindAllStringSubmatch(line, -1) {
+			number, err := strconv.Atoi(m[2])
+			if err != nil {
+				continue
+			}
+			repo := lineRepo
+			if m[1] != "" {
+				repo = matchRepository(m[1], repoNames)
+			}
+			refs[Reference{Repository: repo, Number: number}] = true
+		}
+	}
+
+	return refs
+}
+
+// matchRepository maps a heading or prefix to a known repository name, accepting
+// full names and links. Unknown names are returned normalized.
+func matchRepository(text string, repoNames []string) string {
+	name := strings.Trim(text, "[]`*_ ")
+	if i := strings.Index(name, "]("); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	for _, repoName := range repoNames {
+		if strings.EqualFold(name, repoName) {
+			return repoName
+		}
+	}
+	return strings.ToLower(name)
+}
+
+func sortReferences(refs []Reference) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Repository != refs[j].Repository {
+			return refs[i].Repository < refs[j].Repository
+		}
+		return refs[i].Number < refs[j].Number
+	})
+}
+
+func joinReferences(refs []Reference) string {
+	parts := make([]string, len(refs))
+	for i, ref := range refs {
+		parts[i] = ref.String()
+	}
+	return strings.Join(parts, ", ")
+}
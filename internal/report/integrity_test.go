@@ -0,0 +1,60 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []int
+	}{
+		{name: "bare reference", line: "* Add login (#12)", want: []int{12}},
+		{name: "start of line", line: "#12 was merged", want: []int{12}},
+		{name: "several references", line: "* Login (#1, #2) and #3", want: []int{1, 2, 3}},
+		{name: "owner and repository", line: "* Bump client (acme/api#7)", want: []int{7}},
+		{name: "link", line: "* Login ([#12](https://github.com/acme/api/pull/12))", want: []int{12}},
+		{name: "language name", line: "* Port the parser to C#1 style"},
+		{name: "word before the hash", line: "* Run step#2 of the migration"},
+		{name: "repository without owner", line: "* See api#4"},
+		{name: "URL fragment", line: "* See https://example.com/docs#3"},
+		{name: "number followed by letters", line: "* Ticket #12abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineReferences(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lineReferences(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractReferences(t *testing.T) {
+	markdown := `# Log
+
+## api
+
+### Auth
+* Login (#1)
+* Bump the shared client (acme/web#9)
+* Use C#2 bindings
+
+## 🚧 Work in Progress
+
+* web: Dark mode (#6)
+* Something unattributed (#8)
+`
+	want := References{
+		{Repository: "api", Number: 1}: true,
+		{Repository: "web", Number: 9}: true,
+		{Repository: "web", Number: 6}: true,
+		{Repository: "", Number: 8}:    true,
+	}
+
+	if got := ExtractReferences(markdown, []string{"api", "web"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractReferences() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The marker is an HTML comment so it stays invisible when the report is rendered
+const markerFormat = "<!-- git-log:last-updated %s -->"
+
+var markerPattern = regexp.MustCompile(`(?m)^<!-- git-log:last-updated (\S+) -->\n?`)
+
+// ReadMarker returns the end of the window covered by the last run, as stamped into the report
+func ReadMarker(content string) (time.Time, bool) {
+	matches := markerPattern.FindAllStringSubmatch(content, -1)
+	if len(matches) == 0 {
+		return time.Time{}, false
+	}
+
+	// If the model duplicated the marker, the last one wins
+	t, err := time.Parse(time.RFC3339, matches[len(matches)-1][1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// StripMarker removes any last-updated markers from the report
+func StripMarker(content string) string {
+	return markerPattern.ReplaceAllString(content, "")
+}
+
+// StampMarker replaces any existing marker with one recording until as the end of the covered window
+func StampMarker(content string, until time.Time) string {
+	content = strings.TrimRight(StripMarker(content), "\n")
+	return content + "\n\n" + fmt.Sprintf(markerFormat, until.UTC().Format(time.RFC3339)) + "\n"
+}
+
+// markerHistoryDepth bounds how many earlier versions of the report are searched for a marker
+const markerHistoryDepth = 20
+
+// LastUpdated determines the end of the window the report at reportPath last covered.
+// It prefers the marker stamped into the report and falls back to the marker of the most
+// recent committed version that has one, so a marker lost in a hand edit doesn't reset the
+// window. Commit times aren't used: a report is committed some time after its window ends,
+// so they would leave a gap or an overlap. A zero time means no marker was found.
+func LastUpdated(reportPath string) (time.Time, error) {
+	content, err := os.ReadFile(reportPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("reading report: %w", err)
+	}
+
+	if t, ok := ReadMarker(string(content)); ok {
+		return t, nil
+	}
+
+	return gitLastMarker(reportPath), nil
+}
+
+// gitLastMarker returns the marker of the most recent commit of path whose version has one
+func gitLastMarker(path string) time.Time {
+	dir, name := filepath.Dir(path), filepath.Base(path)
+
+	commits, err := git(dir, "log", fmt.Sprintf("-%d", markerHistoryDepth), "--format=%H", "--", name)
+	if err != nil {
+		// Not a git repository or git is unavailable
+		return time.Time{}
+	}
+
+	for _, commit := range strings.Fields(commits) {
+		content, err := git(dir, "show", commit+":./"+name)
+		if err != nil {
+			continue
+		}
+		if t, ok := ReadMarker(content); ok {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func git(dir string, args ...string) (string, error) {
+	// The workspace is often owned by a different user inside the action container
+	cmd := exec.Command("git", append([]string{"-c", "safe.directory=*"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return string(out), err
+}
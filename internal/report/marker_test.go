@@ -0,0 +1,138 @@
+package report
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkerRoundTrip(t *testing.T) {
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Skip("no timezone data")
+	}
+	until := time.Date(2025, time.March, 31, 23, 59, 59, 0, sydney)
+	content := "# Log\n\n## api\n* Login (#1)\n"
+
+	stamped := StampMarker(content, until)
+	if want := content + "\n<!-- git-log:last-updated 2025-03-31T12:59:59Z -->\n"; stamped != want {
+		t.Errorf("StampMarker() = %q, want %q", stamped, want)
+	}
+	if got, ok := ReadMarker(stamped); !ok || !got.Equal(until) {
+		t.Errorf("ReadMarker() = %v, %v, want %v", got, ok, until)
+	}
+	if got := StripMarker(stamped); got != content+"\n" {
+		t.Errorf("StripMarker() = %q, want %q", got, content+"\n")
+	}
+
+	// Stamping again replaces the marker rather than adding another
+	later := until.AddDate(0, 1, 0)
+	restamped := StampMarker(stamped, later)
+	if count := strings.Count(restamped, "git-log:last-updated"); count != 1 {
+		t.Errorf("restamped report has %d markers, want 1", count)
+	}
+	if got, _ := ReadMarker(restamped); !got.Equal(later) {
+		t.Errorf("ReadMarker() = %v, want %v", got, later)
+	}
+}
+
+func TestReadMarker(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    time.Time
+		wantOK  bool
+	}{
+		{
+			name:    "no marker",
+			content: "# Log\n",
+		},
+		{
+			name:    "malformed time",
+			content: "# Log\n\n<!-- git-log:last-updated yesterday -->\n",
+		},
+		{
+			name:    "duplicated marker, the last one wins",
+			content: "<!-- git-log:last-updated 2025-01-31T00:00:00Z -->\n# Log\n\n<!-- git-log:last-updated 2025-02-28T00:00:00Z -->\n",
+			want:    time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC),
+			wantOK:  true,
+		},
+		{
+			name:    "quoted in a sentence",
+			content: "The marker looks like <!-- git-log:last-updated 2025-01-31T00:00:00Z -->\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ReadMarker(tt.content)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("ReadMarker() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLastUpdatedFallsBackToCommittedMarker(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	run := func(env []string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	path := filepath.Join(dir, "report.md")
+	commit := func(content, date string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run(nil, "add", "report.md")
+		run([]string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date}, "commit", "-q", "-m", "Update report")
+	}
+
+	run(nil, "init", "-q")
+
+	// A report without history has no window yet
+	if err := os.WriteFile(path, []byte("# Log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LastUpdated(path); err != nil || !got.IsZero() {
+		t.Errorf("LastUpdated() = %v, %v, want zero for an uncommitted report", got, err)
+	}
+
+	// Committed the morning after its window ended
+	periodEnd := time.Date(2025, time.February, 28, 23, 59, 59, 0, time.UTC)
+	commit(StampMarker("# Log\n", periodEnd), "2025-03-01T09:00:00Z")
+	// Then edited by hand, losing the marker
+	commit("# Log\n\nEdited.\n", "2025-03-05T09:00:00Z")
+
+	got, err := LastUpdated(path)
+	if err != nil {
+		t.Fatalf("LastUpdated() error = %v", err)
+	}
+	if !got.Equal(periodEnd) {
+		t.Errorf("LastUpdated() = %v, want the committed period end %v", got, periodEnd)
+	}
+}
+
+func TestLastUpdatedMissingReport(t *testing.T) {
+	got, err := LastUpdated(filepath.Join(t.TempDir(), "report.md"))
+	if err != nil || !got.IsZero() {
+		t.Errorf("LastUpdated() = %v, %v, want zero", got, err)
+	}
+}
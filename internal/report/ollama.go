@@ -0,0 +1,134 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// DefaultOllamaURL is where a local Ollama server listens by default
+	DefaultOllamaURL = "http://localhost:11434"
+
+	// DefaultOllamaContextWindow matches what most laptop-sized models handle comfortably
+	DefaultOllamaContextWindow = 8192
+)
+
+// OllamaProvider generates text with a local Ollama server, so no data leaves the machine
+type OllamaProvider struct {
+	BaseURL    string
+	Model      string
+	NumCtx     int
+	HTTPClient *http.Client
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaOptions struct {
+	Temperature *float32 `json:"temperature,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+	NumCtx      int      `json:"num_ctx,omitempty"`
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   any             `json:"format,omitempty"`
+	Options  ollamaOptions   `json:"options"`
+}
+
+type ollamaResponse struct {
+	Message    ollamaMessage `json:"message"`
+	DoneReason string        `json:"done_reason"`
+	Error      string        `json:"error"`
+}
+
+func NewOllamaProvider(baseURL, model string, numCtx int) *OllamaProvider {
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	}
+	if numCtx == 0 {
+		numCtx = DefaultOllamaContextWindow
+	}
+
+	return &OllamaProvider{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Model:   model,
+		NumCtx:  numCtx,
+		// Local generation can be slow, so it is bounded by the caller's context only
+		HTTPClient: &http.Client{},
+	}
+}
+
+// ContextWindow reports the configured num_ctx so the work log can be trimmed to fit
+func (p *OllamaProvider) ContextWindow() int {
+	return p.NumCtx
+}
+
+func (p *OllamaProvider) Generate(ctx context.Context, system, user string, opts GenerateOptions) (string, error) {
+	request := ollamaRequest{
+		Model: p.Model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		Stream: false,
+		Options: ollamaOptions{
+			Temperature: opts.Temperature,
+			NumPredict:  opts.MaxTokens,
+			NumCtx:      p.NumCtx,
+		},
+	}
+	if opts.ResponseSchema != nil {
+		// Ollama constrains output to a JSON schema passed as the format
+		request.Format = opts.ResponseSchema
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.BaseURL+"/api/chat", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("contacting Ollama at %s (is it running?): %w", p.BaseURL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var response ollamaResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("ollama chat failed with status: %d, body: %s", resp.StatusCode, string(body))
+		}
+		return "", fmt.Errorf("parsing ollama response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ollama chat failed with status: %d: %s", resp.StatusCode, response.Error)
+	}
+
+	if response.DoneReason == "length" {
+		fmt.Println("Warning: The report was cut off by the max tokens limit")
+	}
+
+	return response.Message.Content, nil
+}
@@ -0,0 +1,159 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOllamaProviderGenerate(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &gotBody); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		io.WriteString(w, `{"model":"llama3.1","message":{"role":"assistant","content":"# Report"},"done":true,"done_reason":"stop"}`)
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(server.URL+"/", "llama3.1", 0)
+	temperature := float32(0.5)
+	got, err := provider.Generate(context.Background(), "system prompt", "user prompt", GenerateOptions{
+		Temperature: &temperature,
+		MaxTokens:   500,
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got != "# Report" {
+		t.Errorf("Generate() = %q, want %q", got, "# Report")
+	}
+
+	if gotPath != "/api/chat" {
+		t.Errorf("path = %s, want /api/chat", gotPath)
+	}
+	if gotBody["model"] != "llama3.1" {
+		t.Errorf("model = %v, want llama3.1", gotBody["model"])
+	}
+	// A streamed response would arrive as one JSON object per line
+	if gotBody["stream"] != false {
+		t.Errorf("stream = %v, want false", gotBody["stream"])
+	}
+	if _, ok := gotBody["format"]; ok {
+		t.Errorf("format sent without a schema")
+	}
+
+	options, _ := gotBody["options"].(map[string]any)
+	for field, want := range map[string]any{
+		"temperature": 0.5,
+		"num_predict": float64(500),
+		"num_ctx":     float64(DefaultOllamaContextWindow),
+	} {
+		if options[field] != want {
+			t.Errorf("options.%s = %v, want %v", field, options[field], want)
+		}
+	}
+
+	messages, _ := gotBody["messages"].([]any)
+	if len(messages) != 2 {
+		t.Fatalf("messages = %v, want a system and a user message", gotBody["messages"])
+	}
+	for i, want := range []map[string]any{
+		{"role": "system", "content": "system prompt"},
+		{"role": "user", "content": "user prompt"},
+	} {
+		message, _ := messages[i].(map[string]any)
+		if message["role"] != want["role"] || message["content"] != want["content"] {
+			t.Errorf("messages[%d] = %v, want %v", i, message, want)
+		}
+	}
+}
+
+func TestOllamaProviderSendsSchemaAsFormat(t *testing.T) {
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		io.WriteString(w, `{"message":{"role":"assistant","content":"{}"},"done_reason":"stop"}`)
+	}))
+	defer server.Close()
+
+	schema := map[string]any{"type": "object"}
+	if _, err := NewOllamaProvider(server.URL, "llama3.1", 4096).Generate(context.Background(), "s", "u", GenerateOptions{ResponseSchema: schema}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if format, _ := gotBody["format"].(map[string]any); format["type"] != "object" {
+		t.Errorf("format = %v, want the schema", gotBody["format"])
+	}
+	options, _ := gotBody["options"].(map[string]any)
+	if options["num_ctx"] != float64(4096) {
+		t.Errorf("options.num_ctx = %v, want 4096", options["num_ctx"])
+	}
+	for _, field := range []string{"temperature", "num_predict"} {
+		if _, ok := options[field]; ok {
+			t.Errorf("options.%s sent although it was not set", field)
+		}
+	}
+}
+
+func TestOllamaProviderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "API error message",
+			status:  http.StatusNotFound,
+			body:    `{"error":"model \"llama3.1\" not found, try pulling it first"}`,
+			wantErr: `status: 404: model "llama3.1" not found`,
+		},
+		{
+			name:    "non-JSON error body",
+			status:  http.StatusBadGateway,
+			body:    "upstream unavailable",
+			wantErr: "status: 502, body: upstream unavailable",
+		},
+		{
+			name:    "malformed success body",
+			status:  http.StatusOK,
+			body:    "not json",
+			wantErr: "parsing ollama response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			_, err := NewOllamaProvider(server.URL, "llama3.1", 0).Generate(context.Background(), "s", "u", GenerateOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOllamaProviderNotRunning(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	_, err := NewOllamaProvider(url, "llama3.1", 0).Generate(context.Background(), "s", "u", GenerateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "is it running?") {
+		t.Errorf("Generate() error = %v, want a hint that Ollama isn't running", err)
+	}
+}
@@ -0,0 +1,140 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultOpenAIBaseURL is used when no base URL is configured
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// OpenAIProvider generates text with any OpenAI-compatible /chat/completions endpoint,
+// such as OpenAI itself, Azure OpenAI, vLLM or a LiteLLM gateway
+type OpenAIProvider struct {
+	BaseURL      string
+	APIKey       string
+	Organization string
+	Model        string
+	HTTPClient   *http.Client
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model          string          `json:"model"`
+	Messages       []openAIMessage `json:"messages"`
+	Temperature    *float32        `json:"temperature,omitempty"`
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	ResponseFormat any             `json:"response_format,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message      openAIMessage `json:"message"`
+		FinishReason string        `json:"finish_reason"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+func NewOpenAIProvider(baseURL, apiKey, organization, model string) *OpenAIProvider {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+
+	return &OpenAIProvider{
+		BaseURL:      strings.TrimRight(baseURL, "/"),
+		APIKey:       apiKey,
+		Organization: organization,
+		Model:        model,
+		// Generation is bounded by the caller's context rather than a fixed timeout
+		HTTPClient: &http.Client{},
+	}
+}
+
+func (p *OpenAIProvider) Generate(ctx context.Context, system, user string, opts GenerateOptions) (string, error) {
+	request := openAIRequest{
+		Model: p.Model,
+		Messages: []openAIMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+	}
+	if opts.ResponseSchema != nil {
+		request.ResponseFormat = map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   "report",
+				"schema": opts.ResponseSchema,
+				"strict": true,
+			},
+		}
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.BaseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+	if p.Organization != "" {
+		req.Header.Set("OpenAI-Organization", p.Organization)
+	}
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var response openAIResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("chat completion failed with status: %d, body: %s", resp.StatusCode, string(body))
+		}
+		return "", fmt.Errorf("parsing chat completion: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if response.Error != nil {
+			return "", fmt.Errorf("chat completion failed with status: %d: %s", resp.StatusCode, response.Error.Message)
+		}
+		return "", fmt.Errorf("chat completion failed with status: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("chat completion returned no choices")
+	}
+
+	choice := response.Choices[0]
+	if choice.FinishReason == "length" {
+		fmt.Println("Warning: The report was cut off by the max tokens limit")
+	}
+
+	return choice.Message.Content, nil
+}
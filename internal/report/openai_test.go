@@ -0,0 +1,180 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIProviderGenerate(t *testing.T) {
+	var gotPath string
+	var gotHeader http.Header
+	var gotBody map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotHeader = r.Header.Clone()
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &gotBody); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"choices":[{"message":{"role":"assistant","content":"# Report"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	// A trailing slash on a gateway URL must not end up in the path
+	provider := NewOpenAIProvider(server.URL+"/gateway/v1/", "sk-test", "org-test", "gpt-4o-mini")
+	temperature := float32(0.5)
+	got, err := provider.Generate(context.Background(), "system prompt", "user prompt", GenerateOptions{
+		Temperature: &temperature,
+		MaxTokens:   100,
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got != "# Report" {
+		t.Errorf("Generate() = %q, want %q", got, "# Report")
+	}
+
+	if gotPath != "/gateway/v1/chat/completions" {
+		t.Errorf("path = %s, want /gateway/v1/chat/completions", gotPath)
+	}
+	if auth := gotHeader.Get("Authorization"); auth != "Bearer sk-test" {
+		t.Errorf("Authorization = %q, want %q", auth, "Bearer sk-test")
+	}
+	if org := gotHeader.Get("OpenAI-Organization"); org != "org-test" {
+		t.Errorf("OpenAI-Organization = %q, want %q", org, "org-test")
+	}
+	if contentType := gotHeader.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+
+	if gotBody["model"] != "gpt-4o-mini" {
+		t.Errorf("model = %v, want gpt-4o-mini", gotBody["model"])
+	}
+	if gotBody["temperature"] != 0.5 {
+		t.Errorf("temperature = %v, want 0.5", gotBody["temperature"])
+	}
+	if gotBody["max_tokens"] != float64(100) {
+		t.Errorf("max_tokens = %v, want 100", gotBody["max_tokens"])
+	}
+	if _, ok := gotBody["response_format"]; ok {
+		t.Errorf("response_format sent without a schema")
+	}
+
+	messages, _ := gotBody["messages"].([]any)
+	if len(messages) != 2 {
+		t.Fatalf("messages = %v, want a system and a user message", gotBody["messages"])
+	}
+	for i, want := range []map[string]any{
+		{"role": "system", "content": "system prompt"},
+		{"role": "user", "content": "user prompt"},
+	} {
+		message, _ := messages[i].(map[string]any)
+		if message["role"] != want["role"] || message["content"] != want["content"] {
+			t.Errorf("messages[%d] = %v, want %v", i, message, want)
+		}
+	}
+}
+
+func TestOpenAIProviderOmitsOptionalFields(t *testing.T) {
+	var gotHeader http.Header
+	var gotBody map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		io.WriteString(w, `{"choices":[{"message":{"role":"assistant","content":"{}"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	// Self-hosted gateways are often used without a key or organization
+	provider := NewOpenAIProvider(server.URL, "", "", "llama")
+	schema := map[string]any{"type": "object"}
+	if _, err := provider.Generate(context.Background(), "s", "u", GenerateOptions{ResponseSchema: schema}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if _, ok := gotHeader["Authorization"]; ok {
+		t.Errorf("Authorization sent without an API key")
+	}
+	if _, ok := gotHeader["Openai-Organization"]; ok {
+		t.Errorf("OpenAI-Organization sent without an organization")
+	}
+	for _, field := range []string{"temperature", "max_tokens"} {
+		if _, ok := gotBody[field]; ok {
+			t.Errorf("%s sent although it was not set", field)
+		}
+	}
+
+	format, _ := gotBody["response_format"].(map[string]any)
+	if format["type"] != "json_schema" {
+		t.Errorf("response_format = %v, want a json_schema format", gotBody["response_format"])
+	}
+}
+
+func TestOpenAIProviderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "API error message",
+			status:  http.StatusUnauthorized,
+			body:    `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error"}}`,
+			wantErr: "status: 401: Incorrect API key provided",
+		},
+		{
+			name:    "non-JSON error body",
+			status:  http.StatusBadGateway,
+			body:    "upstream unavailable",
+			wantErr: "status: 502, body: upstream unavailable",
+		},
+		{
+			name:    "JSON error without message",
+			status:  http.StatusTooManyRequests,
+			body:    `{}`,
+			wantErr: "status: 429, body: {}",
+		},
+		{
+			name:    "no choices",
+			status:  http.StatusOK,
+			body:    `{"choices":[]}`,
+			wantErr: "returned no choices",
+		},
+		{
+			name:    "malformed success body",
+			status:  http.StatusOK,
+			body:    "not json",
+			wantErr: "parsing chat completion",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			_, err := NewOpenAIProvider(server.URL, "key", "", "gpt-4o").Generate(context.Background(), "s", "u", GenerateOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewOpenAIProviderDefaultsBaseURL(t *testing.T) {
+	if got := NewOpenAIProvider("", "", "", "gpt-4o").BaseURL; got != DefaultOpenAIBaseURL {
+		t.Errorf("BaseURL = %s, want %s", got, DefaultOpenAIBaseURL)
+	}
+}
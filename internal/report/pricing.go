@@ -0,0 +1,57 @@
+package report
+
+import (
+	"sort"
+	"strings"
+)
+
+// ModelPrice is a model's list price in US dollars per million tokens
+type ModelPrice struct {
+	Input  float64
+	Output float64
+}
+
+// ModelPrices are list prices of common models, used for cost estimates only. They are the
+// standard (non-batch, short context) prices from the providers' pricing pages as of
+// October 2025: ai.google.dev/pricing, openai.com/api/pricing and anthropic.com/pricing.
+var ModelPrices = map[string]ModelPrice{
+	"gemini-2.5-flash-lite": {Input: 0.10, Output: 0.40},
+	"gemini-2.5-flash":      {Input: 0.30, Output: 2.50},
+	"gemini-2.5-pro":        {Input: 1.25, Output: 10.00},
+	"gemini-2.0-flash":      {Input: 0.10, Output: 0.40},
+	"gpt-4o-mini":           {Input: 0.15, Output: 0.60},
+	"gpt-4o":                {Input: 2.50, Output: 10.00},
+	"gpt-4.1-mini":          {Input: 0.40, Output: 1.60},
+	"gpt-4.1":               {Input: 2.00, Output: 8.00},
+	"o4-mini":               {Input: 1.10, Output: 4.40},
+	"claude-haiku-4-5":      {Input: 1.00, Output: 5.00},
+	"claude-sonnet-4-5":     {Input: 3.00, Output: 15.00},
+	"claude-sonnet-4":       {Input: 3.00, Output: 15.00},
+	"claude-opus-4-1":       {Input: 15.00, Output: 75.00},
+}
+
+// LookupPrice returns the price of model. Dated or suffixed variants such as
+// "claude-sonnet-4-5-20250929" match the longest known prefix.
+func LookupPrice(model string) (ModelPrice, bool) {
+	if price, ok := ModelPrices[model]; ok {
+		return price, true
+	}
+
+	names := make([]string, 0, len(ModelPrices))
+	for name := range ModelPrices {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	for _, name := range names {
+		if strings.HasPrefix(model, name) {
+			return ModelPrices[name], true
+		}
+	}
+	return ModelPrice{}, false
+}
+
+// Cost estimates the price of a generation in US dollars
+func (p ModelPrice) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.Input + float64(outputTokens)*p.Output) / 1e6
+}
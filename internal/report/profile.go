@@ -0,0 +1,104 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"git-log/internal/processing"
+)
+
+// DefaultProfile is the living accomplishment log written to REPORT_PATH
+const DefaultProfile = "log"
+
+// Profile is a named report style with its own prompt, output path and merge behavior
+type Profile struct {
+	Name        string
+	Description string
+	// SystemPrompt is the profile's system prompt template
+	SystemPrompt string
+	// Audience is passed to the prompts unless AUDIENCE overrides it
+	Audience string
+	// OutputPath is relative to the directory of REPORT_PATH. Empty means REPORT_PATH itself.
+	OutputPath string
+	// Merge sends the existing output to the model so it is updated rather than rewritten
+	Merge bool
+	// Days restricts the work log to the last N days of the window. Zero keeps the whole window.
+	Days int
+	// References marks profiles expected to reference every PR, so their output can be integrity checked
+	References bool
+}
+
+// Profiles are the built-in report profiles
+var Profiles = map[string]Profile{
+	DefaultProfile: {
+		Name:         DefaultProfile,
+		Description:  "Living accomplishment log organized by repository and workstream",
+		SystemPrompt: SystemPrompt,
+		Merge:        true,
+		References:   true,
+	},
+	"cv": {
+		Name:         "cv",
+		Description:  "Impact-focused CV bullets without PR numbers",
+		SystemPrompt: CVSystemPrompt,
+		Audience:     "recruiters and hiring managers",
+		OutputPath:   "cv.md",
+		Merge:        true,
+	},
+	"perf-review": {
+		Name:         "perf-review",
+		Description:  "Performance review self-assessment grouped by competency, with metrics",
+		SystemPrompt: PerfReviewSystemPrompt,
+		Audience:     "my manager and the performance review committee",
+		OutputPath:   "perf-review.md",
+	},
+	"standup": {
+		Name:         "standup",
+		Description:  "Terse summary of the last 7 days for a weekly standup",
+		SystemPrompt: StandupSystemPrompt,
+		Audience:     "my team",
+		OutputPath:   "standup.md",
+		Days:         7,
+	},
+	"manager": {
+		Name:         "manager",
+		Description:  "Themes, progress and risks for a manager brief",
+		SystemPrompt: ManagerSystemPrompt,
+		Audience:     "my engineering manager",
+		OutputPath:   "manager-brief.md",
+	},
+}
+
+// LookupProfile returns the built-in profile with the given name
+func LookupProfile(name string) (Profile, error) {
+	profile, ok := Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q, expected one of: %s", name, strings.Join(ProfileNames(), ", "))
+	}
+	return profile, nil
+}
+
+// ProfileNames returns the names of all built-in profiles in alphabetical order
+func ProfileNames() []string {
+	names := make([]string, 0, len(Profiles))
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WorkLog returns the part of the work log this profile reports on
+func (p Profile) WorkLog(workLog processing.WorkLog) processing.WorkLog {
+	if p.Days == 0 {
+		return workLog
+	}
+
+	end := workLog.Summary.Period.End
+	start := end.AddDate(0, 0, -p.Days)
+	if start.Before(workLog.Summary.Period.Start) {
+		return workLog
+	}
+	return processing.FilterWorkLog(workLog, start, end)
+}
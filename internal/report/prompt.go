@@ -0,0 +1,100 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+)
+
+// DefaultUserPrompt is the user prompt template used when no USER_PROMPT_PATH is given
+const DefaultUserPrompt = `Here is the existing accomplishment report and the work log for {{ .Username }} covering {{ .PeriodStart }} to {{ .PeriodEnd }} across {{ .RepoCount }} repositories.
+{{- with .Audience }}
+
+The report is written for {{ . }}. Adjust tone and level of detail accordingly.
+{{- end }}
+
+Please update and merge the report according to your system instructions.
+
+report.md:
+
+{{ .Report }}
+
+work_log.json:
+
+{{ .WorkLog }}
+`
+
+// Prompts holds the system and user prompt templates
+type Prompts struct {
+	System string
+	User   string
+}
+
+// PromptData is what prompt templates are executed with
+type PromptData struct {
+	Username    string
+	PeriodStart string
+	PeriodEnd   string
+	RepoCount   int
+	Audience    string
+	// Report is the existing report, empty on the first run
+	Report string
+	// WorkLog is the work log as JSON
+	WorkLog string
+}
+
+// DefaultPrompts returns the embedded prompts
+func DefaultPrompts() Prompts {
+	return Prompts{
+		System: SystemPrompt,
+		User:   DefaultUserPrompt,
+	}
+}
+
+// LoadPrompts reads prompt templates from the given paths, falling back to the
+// embedded defaults for any path that is empty
+func LoadPrompts(systemPath, userPath string) (Prompts, error) {
+	prompts := DefaultPrompts()
+
+	if systemPath != "" {
+		systemBytes, err := os.ReadFile(systemPath)
+		if err != nil {
+			return prompts, fmt.Errorf("reading system prompt: %w", err)
+		}
+		prompts.System = string(systemBytes)
+	}
+
+	if userPath != "" {
+		userBytes, err := os.ReadFile(userPath)
+		if err != nil {
+			return prompts, fmt.Errorf("reading user prompt: %w", err)
+		}
+		prompts.User = string(userBytes)
+	}
+
+	return prompts, nil
+}
+
+// RenderSystem executes the system prompt template
+func (p Prompts) RenderSystem(data PromptData) (string, error) {
+	return renderPrompt("system", p.System, data)
+}
+
+// RenderUser executes the user prompt template
+func (p Prompts) RenderUser(data PromptData) (string, error) {
+	return renderPrompt("user", p.User, data)
+}
+
+func renderPrompt(name, text string, data PromptData) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s prompt: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering %s prompt: %w", name, err)
+	}
+	return buf.String(), nil
+}
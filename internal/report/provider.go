@@ -0,0 +1,56 @@
+package report
+
+import (
+	"context"
+	"fmt"
+)
+
+// Supported provider names
+const (
+	ProviderGemini    = "gemini"
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+// Provider generates text from a system prompt and a user prompt
+type Provider interface {
+	Generate(ctx context.Context, system, user string, opts GenerateOptions) (string, error)
+}
+
+// GenerateOptions tunes a single generation. Zero values leave the provider's defaults in place.
+type GenerateOptions struct {
+	Temperature *float32
+	MaxTokens   int
+	// ResponseSchema asks for JSON output matching this JSON schema, where the provider supports it
+	ResponseSchema map[string]any
+}
+
+// ProviderConfig selects and configures a provider
+type ProviderConfig struct {
+	Name   string
+	Model  string
+	APIKey string
+	// BaseURL overrides the provider's default endpoint
+	BaseURL string
+	// Organization is sent as the OpenAI-Organization header
+	Organization string
+	// ContextWindow is the number of tokens a local model is run with
+	ContextWindow int
+}
+
+// NewProvider creates the provider named in cfg
+func NewProvider(ctx context.Context, cfg ProviderConfig) (Provider, error) {
+	switch cfg.Name {
+	case "", ProviderGemini:
+		return NewGeminiProvider(ctx, cfg.APIKey, cfg.Model)
+	case ProviderOpenAI:
+		return NewOpenAIProvider(cfg.BaseURL, cfg.APIKey, cfg.Organization, cfg.Model), nil
+	case ProviderAnthropic:
+		return NewAnthropicProvider(cfg.BaseURL, cfg.APIKey, cfg.Model), nil
+	case ProviderOllama:
+		return NewOllamaProvider(cfg.BaseURL, cfg.Model, cfg.ContextWindow), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", cfg.Name)
+	}
+}
@@ -0,0 +1,447 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SectionMergeInstructions are appended to the system prompt when only part of the report is sent
+const SectionMergeInstructions = `
+
+Partial Update:
+
+To keep the prompt small, the existing report you are given is an excerpt: only the "## [Repository Name]" sections for repositories in the work log, and the Work in Progress entries for those repositories. Every other section and entry is kept as it is and spliced back by the tool. Output only the updated versions of the sections you were given, plus new sections for repositories that are not in the excerpt yet. Use the repository names as headings exactly as they appear. In the Work in Progress section, output only the entries you were given that are still in progress plus new ones for these repositories; if none remain, leave the section out.`
+
+// Document is a Markdown report split into its "##" sections, so individual
+// sections can be sent to the model and spliced back without touching the rest
+type Document struct {
+	// Preamble is everything before the first "##" heading, usually the title
+	Preamble []string
+	Sections []*Section
+}
+
+// Section is a "## repository" section of the report
+type Section struct {
+	Heading string
+	// Intro is the content between the heading and the first workstream
+	Intro       []string
+	Subsections []*Subsection
+}
+
+// Subsection is a "### workstream" subsection and its bullets
+type Subsection struct {
+	Heading string
+	Lines   []string
+}
+
+// ParseDocument splits a Markdown report into sections. Rendering the result with
+// String gives back the original text, so untouched sections stay byte for byte the same.
+func ParseDocument(markdown string) *Document {
+	doc := &Document{}
+	var section *Section
+	var subsection *Subsection
+	inFence := false
+
+	for _, line := range strings.Split(strings.TrimRight(markdown, "\n"), "\n") {
+		// Headings inside code blocks are content
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+
+		switch {
+		case !inFence && strings.HasPrefix(line, "## "):
+			section = &Section{Heading: strings.TrimSpace(strings.TrimPrefix(line, "## "))}
+			subsection = nil
+			doc.Sections = append(doc.Sections, section)
+		case !inFence && section != nil && strings.HasPrefix(line, "### "):
+			subsection = &Subsection{Heading: strings.TrimSpace(strings.TrimPrefix(line, "### "))}
+			section.Subsections = append(section.Subsections, subsection)
+		case subsection != nil:
+			subsection.Lines = append(subsection.Lines, line)
+		case section != nil:
+			section.Intro = append(section.Intro, line)
+		default:
+			doc.Preamble = append(doc.Preamble, line)
+		}
+	}
+
+	return doc
+}
+
+// String renders the document back to Markdown
+func (d *Document) String() string {
+	lines := append([]string{}, d.Preamble...)
+	for i, section := range d.Sections {
+		if i > 0 && lines[len(lines)-1] != "" {
+			// Sections spliced in from the model may lack the blank line before the next heading
+			lines = append(lines, "")
+		}
+		lines = append(lines, section.lines()...)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// lines renders the section including its heading
+func (s *Section) lines() []string {
+	lines := append([]string{"## " + s.Heading}, s.Intro...)
+	for _, subsection := range s.Subsections {
+		lines = append(lines, "### "+subsection.Heading)
+		lines = append(lines, subsection.Lines...)
+	}
+	return lines
+}
+
+// WorkInProgress reports whether this is the work in progress section
+func (s *Section) WorkInProgress() bool {
+	return strings.Contains(strings.ToLower(s.Heading), "work in progress")
+}
+
+// key identifies the repository a section belongs to, so headings written
+// slightly differently by the model still match
+func (s *Section) key(repoNames []string) string {
+	if s.WorkInProgress() {
+		return "work in progress"
+	}
+	return strings.ToLower(matchRepository(s.Heading, repoNames))
+}
+
+// Excerpt returns the sections for the given repositories plus their entries in the work
+// in progress section, which are the only parts of the report a work log can change
+func (d *Document) Excerpt(repoNames []string) *Document {
+	wanted := make(map[string]bool)
+	for _, name := range repoNames {
+		wanted[strings.ToLower(name)] = true
+	}
+
+	excerpt := &Document{}
+	for _, section := range d.Sections {
+		switch {
+		case section.WorkInProgress():
+			lead, items, _ := splitWorkInProgress(section, repoNames)
+			sent := &Section{Heading: section.Heading, Intro: lead}
+			for _, item := range items {
+				if wanted[strings.ToLower(item.repo)] {
+					sent.Intro = append(sent.Intro, item.lines...)
+				}
+			}
+			if len(sent.Intro) > len(lead) {
+				excerpt.Sections = append(excerpt.Sections, sent)
+			}
+		case wanted[section.key(repoNames)]:
+			excerpt.Sections = append(excerpt.Sections, section)
+		}
+	}
+	return excerpt
+}
+
+// Splice merges the sections of updated into the document. Only the sections that were
+// sent are replaced, in place, and sections for repositories in the work log that the
+// report doesn't have yet are added before the work in progress section. Anything else
+// the update returns would overwrite parts of the report the model never saw, so it is
+// left out and its heading returned. Work in progress entries are merged one by one,
+// see mergeWorkInProgress.
+func (d *Document) Splice(updated *Document, sent *Document, repoNames []string) (*Document, []string) {
+	replacements := make(map[string]*Section)
+	var added []*Section
+	var wip *Section
+	var ignored []string
+
+	existing := make(map[string]bool)
+	for _, section := range d.Sections {
+		existing[section.key(repoNames)] = true
+	}
+	wasSent := make(map[string]bool)
+	for _, section := range sent.Sections {
+		wasSent[section.key(repoNames)] = true
+	}
+	inWorkLog := make(map[string]bool)
+	for _, name := range repoNames {
+		inWorkLog[strings.ToLower(name)] = true
+	}
+
+	for _, section := range updated.Sections {
+		key := section.key(repoNames)
+		switch {
+		case section.WorkInProgress():
+			wip = section
+		case replacements[key] != nil:
+			ignored = append(ignored, section.Heading)
+		case wasSent[key]:
+			replacements[key] = section
+		case !existing[key] && inWorkLog[key]:
+			replacements[key] = section
+			added = append(added, section)
+		default:
+			ignored = append(ignored, section.Heading)
+		}
+	}
+
+	merged := &Document{Preamble: d.Preamble}
+	if len(merged.Preamble) == 0 {
+		merged.Preamble = updated.Preamble
+	}
+
+	var previousWIP *Section
+	for _, section := range d.Sections {
+		if section.WorkInProgress() {
+			previousWIP = section
+			continue
+		}
+		if replacement := replacements[section.key(repoNames)]; replacement != nil {
+			merged.Sections = append(merged.Sections, replacement)
+		} else {
+			merged.Sections = append(merged.Sections, section)
+		}
+	}
+
+	merged.Sections = append(merged.Sections, added...)
+
+	if section := mergeWorkInProgress(previousWIP, sent.workInProgress(), wip, repoNames); section != nil {
+		merged.Sections = append(merged.Sections, section)
+	}
+
+	return merged, ignored
+}
+
+// mergeWorkInProgress updates the work in progress section one entry at a time, so a
+// batch only touches the entries of its own repositories. Entries that were sent and not
+// returned were promoted and are removed, returned entries that weren't there are added
+// at the end and everything else is kept in place. A section left without entries is dropped.
+func mergeWorkInProgress(previous, sent, returned *Section, repoNames []string) *Section {
+	switch {
+	case returned == nil && sent == nil:
+		return previous
+	case previous == nil:
+		return returned
+	}
+
+	_, sentItems, _ := splitWorkInProgress(sent, repoNames)
+	_, returnedItems, _ := splitWorkInProgress(returned, repoNames)
+	lead, items, trail := splitWorkInProgress(previous, repoNames)
+
+	wasSent := make(map[string]bool)
+	for _, item := range sentItems {
+		wasSent[item.String()] = true
+	}
+	wasReturned := make(map[string]bool)
+	for _, item := range returnedItems {
+		wasReturned[item.String()] = true
+	}
+
+	// Entries returned unchanged keep their place
+	merged := &Section{Heading: previous.Heading, Intro: lead, Subsections: previous.Subsections}
+	present := make(map[string]bool)
+	for _, item := range items {
+		if !wasSent[item.String()] || wasReturned[item.String()] {
+			merged.Intro = append(merged.Intro, item.lines...)
+			present[item.String()] = true
+		}
+	}
+	for _, item := range returnedItems {
+		if !present[item.String()] {
+			merged.Intro = append(merged.Intro, item.lines...)
+			present[item.String()] = true
+		}
+	}
+
+	if len(present) == 0 && len(merged.Subsections) == 0 {
+		return nil
+	}
+	merged.Intro = append(merged.Intro, trail...)
+	return merged
+}
+
+// wipItem is an entry of the work in progress section: a bullet and its continuation lines
+type wipItem struct {
+	// repo is the repository the bullet names, empty when it names none from the work log
+	repo  string
+	lines []string
+}
+
+func (i wipItem) String() string {
+	return strings.TrimSpace(strings.Join(i.lines, "\n"))
+}
+
+// splitWorkInProgress splits the intro of a work in progress section into its entries and
+// the lines before and after them. A nil section has none.
+func splitWorkInProgress(section *Section, repoNames []string) (lead []string, items []wipItem, trail []string) {
+	if section == nil {
+		return nil, nil, nil
+	}
+
+	lines := section.Intro
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	trail = lines[end:]
+
+	for _, line := range lines[:end] {
+		switch {
+		case isBullet(line) && line == strings.TrimLeft(line, " \t"):
+			item := wipItem{lines: []string{line}}
+			if m := wipBulletPattern.FindStringSubmatch(line); m != nil {
+				for _, name := range repoNames {
+					if strings.EqualFold(matchRepository(m[1], repoNames), name) {
+						item.repo = name
+					}
+				}
+			}
+			items = append(items, item)
+		case len(items) > 0:
+			items[len(items)-1].lines = append(items[len(items)-1].lines, line)
+		default:
+			lead = append(lead, line)
+		}
+	}
+	return lead, items, trail
+}
+
+func (d *Document) workInProgress() *Section {
+	for _, section := range d.Sections {
+		if section.WorkInProgress() {
+			return section
+		}
+	}
+	return nil
+}
+
+// Append merges a rendered report into the document without losing history, for
+// renderers that only see the current window. Rendered bullets are added to the
+// matching repository and subsection, replacing existing bullets for the same pull
+// requests so a PR moves from one category to another instead of being listed twice.
+// Every section the rendered report doesn't mention is kept. The preamble is taken from
+// the rendered report, when it has one, so its summary line is never left describing an
+// earlier window.
+func (d *Document) Append(rendered *Document, repoNames []string) *Document {
+	byKey := make(map[string]*Section)
+	for _, section := range rendered.Sections {
+		byKey[section.key(repoNames)] = section
+	}
+
+	merged := &Document{Preamble: rendered.Preamble}
+	if len(merged.Preamble) == 0 {
+		merged.Preamble = d.Preamble
+	}
+
+	appended := make(map[string]bool)
+	var wip []*Section
+	for _, section := range d.Sections {
+		key := section.key(repoNames)
+		switch {
+		case section.WorkInProgress():
+			wip = append(wip, section)
+		case byKey[key] != nil && !appended[key]:
+			merged.Sections = append(merged.Sections, section.appendSection(byKey[key]))
+			appended[key] = true
+		default:
+			merged.Sections = append(merged.Sections, section)
+		}
+	}
+
+	// Repositories new to the report go before the work in progress section
+	for _, section := range rendered.Sections {
+		if key := section.key(repoNames); !appended[key] {
+			merged.Sections = append(merged.Sections, section)
+			appended[key] = true
+		}
+	}
+	merged.Sections = append(merged.Sections, wip...)
+
+	return merged
+}
+
+// appendSection adds the bullets of rendered to a copy of the section
+func (s *Section) appendSection(rendered *Section) *Section {
+	replaced := make(map[int]bool)
+	for _, subsection := range rendered.Subsections {
+		for _, line := range subsection.Lines {
+			for _, number := range lineReferences(line) {
+				replaced[number] = true
+			}
+		}
+	}
+
+	merged := &Section{Heading: s.Heading, Intro: s.Intro}
+	for _, subsection := range s.Subsections {
+		var lines []string
+		kept := false
+		for _, line := range subsection.Lines {
+			if isBullet(line) && referencesAny(line, replaced) {
+				continue
+			}
+			lines = append(lines, line)
+			kept = kept || strings.TrimSpace(line) != ""
+		}
+		if kept {
+			merged.Subsections = append(merged.Subsections, &Subsection{Heading: subsection.Heading, Lines: lines})
+		}
+	}
+
+	for _, subsection := range rendered.Subsections {
+		existing := merged.subsection(subsection.Heading)
+		if existing == nil {
+			merged.Subsections = append(merged.Subsections, subsection)
+			continue
+		}
+		existing.appendLines(subsection.Lines)
+	}
+
+	return merged
+}
+
+func (s *Section) subsection(heading string) *Subsection {
+	for _, subsection := range s.Subsections {
+		if strings.EqualFold(subsection.Heading, heading) {
+			return subsection
+		}
+	}
+	return nil
+}
+
+// appendLines adds the non-blank lines the subsection doesn't have yet after its last one
+func (s *Subsection) appendLines(lines []string) {
+	present := make(map[string]bool)
+	end := 0
+	for i, line := range s.Lines {
+		present[line] = true
+		if strings.TrimSpace(line) != "" {
+			end = i + 1
+		}
+	}
+
+	var added []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" && !present[line] {
+			added = append(added, line)
+			present[line] = true
+		}
+	}
+
+	s.Lines = append(append(append([]string{}, s.Lines[:end]...), added...), s.Lines[end:]...)
+}
+
+func isBullet(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "- ")
+}
+
+// lineReferences returns the pull request numbers referenced on a line
+func lineReferences(line string) []int {
+	var numbers []int
+	for _, match := range referencePattern.FindAllStringSubmatch(line, -1) {
+		if number, err := strconv.Atoi(match[2]); err == nil {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+func referencesAny(line string, numbers map[int]bool) bool {
+	for _, number := range lineReferences(line) {
+		if numbers[number] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,317 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sectionsReport = `# Log
+
+Intro.
+
+## api
+
+### Auth
+* Login (#1)
+
+## web
+
+### UI
+* Page (#2)
+
+## 🚧 Work in Progress
+
+* api: Rate limiting (#5)
+* web: Dark mode (#6)
+`
+
+func TestParseDocumentRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+	}{
+		{name: "report", markdown: sectionsReport},
+		{name: "preamble only", markdown: "# Log\n\nNothing yet.\n"},
+		{name: "headings in a code block", markdown: "# Log\n\n## api\n\n```\n## not a section\n### nor a workstream\n```\n"},
+		{name: "section without workstreams", markdown: "## api\nSome notes.\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseDocument(tt.markdown).String(); got != tt.markdown {
+				t.Errorf("ParseDocument().String() = %q, want %q", got, tt.markdown)
+			}
+		})
+	}
+}
+
+func TestParseDocumentIgnoresHeadingsInCodeBlocks(t *testing.T) {
+	doc := ParseDocument("## api\n\n```\n## not a section\n```\n")
+	if len(doc.Sections) != 1 {
+		t.Fatalf("got %d sections, want 1", len(doc.Sections))
+	}
+	if len(doc.Sections[0].Subsections) != 0 {
+		t.Errorf("got %d subsections, want 0", len(doc.Sections[0].Subsections))
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name      string
+		repoNames []string
+		want      string
+	}{
+		{
+			name:      "section and its work in progress entries",
+			repoNames: []string{"api"},
+			want:      "## api\n\n### Auth\n* Login (#1)\n\n## 🚧 Work in Progress\n\n* api: Rate limiting (#5)\n",
+		},
+		{
+			name:      "new repository",
+			repoNames: []string{"cli"},
+			want:      "\n",
+		},
+		{
+			name:      "every repository",
+			repoNames: []string{"api", "web"},
+			want:      "## api\n\n### Auth\n* Login (#1)\n\n## web\n\n### UI\n* Page (#2)\n\n## 🚧 Work in Progress\n\n* api: Rate limiting (#5)\n* web: Dark mode (#6)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseDocument(sectionsReport).Excerpt(tt.repoNames).String(); got != tt.want {
+				t.Errorf("Excerpt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplice(t *testing.T) {
+	tests := []struct {
+		name        string
+		repoNames   []string
+		updated     string
+		want        string
+		wantIgnored []string
+	}{
+		{
+			name:      "replaces the sent section in place",
+			repoNames: []string{"api"},
+			updated:   "## api\n\n### Auth\n* Login (#1)\n* Logout (#3)\n\n## 🚧 Work in Progress\n\n* api: Rate limiting (#5)\n",
+			want: `# Log
+
+Intro.
+
+## api
+
+### Auth
+* Login (#1)
+* Logout (#3)
+
+## web
+
+### UI
+* Page (#2)
+
+## 🚧 Work in Progress
+
+* api: Rate limiting (#5)
+* web: Dark mode (#6)
+`,
+		},
+		{
+			name:      "adds a new repository before the work in progress section",
+			repoNames: []string{"cli"},
+			updated:   "## cli\n\n### Flags\n* Flags (#9)\n",
+			want: `# Log
+
+Intro.
+
+## api
+
+### Auth
+* Login (#1)
+
+## web
+
+### UI
+* Page (#2)
+
+## cli
+
+### Flags
+* Flags (#9)
+
+## 🚧 Work in Progress
+
+* api: Rate limiting (#5)
+* web: Dark mode (#6)
+`,
+		},
+		{
+			name:        "ignores a rewrite of a section that was not sent",
+			repoNames:   []string{"api"},
+			updated:     "## api\n\n### Auth\n* Login (#1)\n\n## web\n\n### UI\n* Rewritten\n\n## 🚧 Work in Progress\n\n* api: Rate limiting (#5)\n",
+			want:        sectionsReport,
+			wantIgnored: []string{"web"},
+		},
+		{
+			name:        "ignores repositories that are not in the work log",
+			repoNames:   []string{"api"},
+			updated:     "## api\n\n### Auth\n* Login (#1)\n\n## Invented\n\n### Stuff\n* Made up (#99)\n\n## 🚧 Work in Progress\n\n* api: Rate limiting (#5)\n",
+			want:        sectionsReport,
+			wantIgnored: []string{"Invented"},
+		},
+		{
+			name:        "keeps the first of duplicate sections",
+			repoNames:   []string{"api"},
+			updated:     "## api\n\n### Auth\n* Login (#1)\n\n## api\n\n### Other\n* Duplicate\n\n## 🚧 Work in Progress\n\n* api: Rate limiting (#5)\n",
+			want:        sectionsReport,
+			wantIgnored: []string{"api"},
+		},
+		{
+			name:      "matches headings written with the owner",
+			repoNames: []string{"api"},
+			updated:   "## acme/api\n\n### Auth\n* Login (#1)\n\n## 🚧 Work in Progress\n\n* api: Rate limiting (#5)\n",
+			want: `# Log
+
+Intro.
+
+## acme/api
+
+### Auth
+* Login (#1)
+
+## web
+
+### UI
+* Page (#2)
+
+## 🚧 Work in Progress
+
+* api: Rate limiting (#5)
+* web: Dark mode (#6)
+`,
+		},
+		{
+			name:      "removes promoted work in progress entries",
+			repoNames: []string{"api"},
+			updated:   "## api\n\n### Auth\n* Login (#1)\n\n### Limits\n* Rate limiting (#5)\n",
+			want: `# Log
+
+Intro.
+
+## api
+
+### Auth
+* Login (#1)
+
+### Limits
+* Rate limiting (#5)
+
+## web
+
+### UI
+* Page (#2)
+
+## 🚧 Work in Progress
+
+* web: Dark mode (#6)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := ParseDocument(sectionsReport)
+			sent := doc.Excerpt(tt.repoNames)
+			got, ignored := doc.Splice(ParseDocument(tt.updated), sent, tt.repoNames)
+			if got.String() != tt.want {
+				t.Errorf("Splice() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if !reflect.DeepEqual(ignored, tt.wantIgnored) {
+				t.Errorf("Splice() ignored = %v, want %v", ignored, tt.wantIgnored)
+			}
+		})
+	}
+}
+
+func TestMergeWorkInProgress(t *testing.T) {
+	repoNames := []string{"api", "web", "cli"}
+	section := func(lines ...string) *Section {
+		return &Section{Heading: "🚧 Work in Progress", Intro: lines}
+	}
+
+	tests := []struct {
+		name                     string
+		previous, sent, returned *Section
+		want                     *Section
+	}{
+		{
+			name:     "nothing sent or returned",
+			previous: section("", "* api: Rate limiting (#5)"),
+			want:     section("", "* api: Rate limiting (#5)"),
+		},
+		{
+			name:     "no previous section",
+			returned: section("", "* cli: Completion (#10)"),
+			want:     section("", "* cli: Completion (#10)"),
+		},
+		{
+			name:     "entries of other repositories are kept",
+			previous: section("", "* api: Rate limiting (#5)", "* web: Dark mode (#6)", ""),
+			sent:     section("", "* api: Rate limiting (#5)"),
+			returned: section("", "* api: Rate limiting, now with tests (#5)"),
+			want:     section("", "* web: Dark mode (#6)", "* api: Rate limiting, now with tests (#5)", ""),
+		},
+		{
+			name:     "continuation lines move with their entry",
+			previous: section("", "* api: Rate limiting (#5)", "  - still reviewing", "* web: Dark mode (#6)"),
+			sent:     section("", "* api: Rate limiting (#5)", "  - still reviewing"),
+			want:     section("", "* web: Dark mode (#6)"),
+		},
+		{
+			name:     "returned entries are not duplicated",
+			previous: section("", "* web: Dark mode (#6)"),
+			returned: section("", "* web: Dark mode (#6)", "* cli: Completion (#10)"),
+			want:     section("", "* web: Dark mode (#6)", "* cli: Completion (#10)"),
+		},
+		{
+			name:     "dropped once empty",
+			previous: section("", "* api: Rate limiting (#5)"),
+			sent:     section("", "* api: Rate limiting (#5)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeWorkInProgress(tt.previous, tt.sent, tt.returned, repoNames)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeWorkInProgress() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	existing := "# Log\n\n_Jan 1 to Jan 31: 2 pull requests._\n\n## api\n\nService API.\n\n### Merged\n* Old thing ([#3](u3))\n\n### In Progress\n* New thing ([#7](u7))\n\n## web\n\n### Merged\n* Page ([#2](u2))\n\n## 🚧 Work in Progress\n\n* api: something\n"
+	rendered := "# Log\n\n_Feb 1 to Feb 28: 2 pull requests._\n\n## api\n\n### Merged\n* New thing ([#7](u7))\n\n### Commits\n* another ([`def5678`](d))\n\n## cli\n\n### In Progress\n* Flags ([#1](u1))\n"
+	want := "# Log\n\n_Feb 1 to Feb 28: 2 pull requests._\n\n## api\n\nService API.\n\n### Merged\n* Old thing ([#3](u3))\n* New thing ([#7](u7))\n\n### Commits\n* another ([`def5678`](d))\n\n## web\n\n### Merged\n* Page ([#2](u2))\n\n## cli\n\n### In Progress\n* Flags ([#1](u1))\n\n## 🚧 Work in Progress\n\n* api: something\n"
+
+	got := ParseDocument(existing).Append(ParseDocument(rendered), []string{"api", "cli"}).String()
+	if got != want {
+		t.Errorf("Append() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAppendKeepsThePreambleWhenNoneIsRendered(t *testing.T) {
+	existing := "# Log\n\nMy notes.\n\n## api\n\n### Merged\n* Old thing ([#3](u3))\n"
+	rendered := "## api\n\n### Merged\n* New thing ([#7](u7))\n"
+	want := "# Log\n\nMy notes.\n\n## api\n\n### Merged\n* Old thing ([#3](u3))\n* New thing ([#7](u7))\n"
+
+	got := ParseDocument(existing).Append(ParseDocument(rendered), []string{"api"}).String()
+	if got != want {
+		t.Errorf("Append() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,210 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StructuredReport is the accomplishment log as structured data, so the
+// Markdown layout is controlled by Markdown() rather than by the model
+type StructuredReport struct {
+	Title          string                 `json:"title"`
+	Repositories   []StructuredRepository `json:"repositories"`
+	WorkInProgress []WorkInProgressItem   `json:"work_in_progress"`
+}
+
+// StructuredRepository groups a repository's workstreams
+type StructuredRepository struct {
+	Name        string       `json:"name"`
+	Workstreams []Workstream `json:"workstreams"`
+}
+
+// Workstream is a feature or theme spanning one or more pull requests
+type Workstream struct {
+	Title           string           `json:"title"`
+	Accomplishments []Accomplishment `json:"accomplishments"`
+}
+
+// Accomplishment is a single bullet with the pull requests backing it
+type Accomplishment struct {
+	Summary      string `json:"summary"`
+	PullRequests []int  `json:"pull_requests"`
+}
+
+// WorkInProgressItem is preliminary work not yet ready for the main report
+type WorkInProgressItem struct {
+	Repository   string `json:"repository"`
+	Summary      string `json:"summary"`
+	PullRequests []int  `json:"pull_requests"`
+}
+
+// ReportSchema is the JSON schema of StructuredReport sent as the provider's response schema.
+// Every property is required and no others are allowed, as strict modes demand.
+var ReportSchema = map[string]any{
+	"type":                 "object",
+	"additionalProperties": false,
+	"required":             []string{"title", "repositories", "work_in_progress"},
+	"properties": map[string]any{
+		"title": map[string]any{"type": "string"},
+		"repositories": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type":                 "object",
+				"additionalProperties": false,
+				"required":             []string{"name", "workstreams"},
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string"},
+					"workstreams": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type":                 "object",
+							"additionalProperties": false,
+							"required":             []string{"title", "accomplishments"},
+							"properties": map[string]any{
+								"title": map[string]any{"type": "string"},
+								"accomplishments": map[string]any{
+									"type":  "array",
+									"items": accomplishmentSchema("summary"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"work_in_progress": map[string]any{
+			"type":  "array",
+			"items": accomplishmentSchema("repository", "summary"),
+		},
+	},
+}
+
+// accomplishmentSchema builds an object schema of the given string fields plus a
+// "pull_requests" list of PR numbers, all of them required
+func accomplishmentSchema(stringFields ...string) map[string]any {
+	properties := map[string]any{}
+	for _, field := range stringFields {
+		properties[field] = map[string]any{"type": "string"}
+	}
+	properties["pull_requests"] = map[string]any{
+		"type":  "array",
+		"items": map[string]any{"type": "integer"},
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             append(stringFields, "pull_requests"),
+		"properties":           properties,
+	}
+}
+
+// StructuredOutputInstructions replaces the system prompt's Markdown output instructions in structured mode
+const StructuredOutputInstructions = `
+
+Structured Output:
+
+Ignore the instruction above to output a Markdown file. Instead, respond with a single JSON object matching this schema, and nothing else:
+
+{"title": string, "repositories": [{"name": string, "workstreams": [{"title": string, "accomplishments": [{"summary": string, "pull_requests": [number]}]}]}], "work_in_progress": [{"repository": string, "summary": string, "pull_requests": [number]}]}
+
+Each repository corresponds to a "## [Repository Name]" section, each workstream to a "### [Feature Title]" and each accomplishment to a bullet. Put PR numbers in "pull_requests" instead of the summary text. Carry over every section of the existing report, updated as instructed.`
+
+// ParseStructuredReport decodes and validates a model response against the report structure
+func ParseStructuredReport(text string) (*StructuredReport, error) {
+	text = strings.TrimSpace(text)
+
+	// Some models wrap JSON in a code fence despite the response schema
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(text, "```")
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.DisallowUnknownFields()
+
+	var report StructuredReport
+	if err := decoder.Decode(&report); err != nil {
+		return nil, fmt.Errorf("decoding structured report: %w", err)
+	}
+
+	if err := report.Validate(); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Validate checks that every section and bullet has content
+func (r *StructuredReport) Validate() error {
+	for i, repo := range r.Repositories {
+		if strings.TrimSpace(repo.Name) == "" {
+			return fmt.Errorf("repository %d has no name", i+1)
+		}
+		for j, workstream := range repo.Workstreams {
+			if strings.TrimSpace(workstream.Title) == "" {
+				return fmt.Errorf("workstream %d in %s has no title", j+1, repo.Name)
+			}
+			for k, accomplishment := range workstream.Accomplishments {
+				if strings.TrimSpace(accomplishment.Summary) == "" {
+					return fmt.Errorf("accomplishment %d in %s / %s has no summary", k+1, repo.Name, workstream.Title)
+				}
+			}
+		}
+	}
+
+	for i, item := range r.WorkInProgress {
+		if strings.TrimSpace(item.Summary) == "" {
+			return fmt.Errorf("work in progress item %d has no summary", i+1)
+		}
+	}
+
+	return nil
+}
+
+// Markdown renders the report in the same layout the system prompt asks for
+func (r *StructuredReport) Markdown() string {
+	var b strings.Builder
+
+	title := r.Title
+	if strings.TrimSpace(title) == "" {
+		title = "Developer Accomplishment Log"
+	}
+	fmt.Fprintf(&b, "# %s\n", title)
+
+	for _, repo := range r.Repositories {
+		fmt.Fprintf(&b, "\n## %s\n", repo.Name)
+		for _, workstream := range repo.Workstreams {
+			fmt.Fprintf(&b, "\n### %s\n", workstream.Title)
+			for _, accomplishment := range workstream.Accomplishments {
+				fmt.Fprintf(&b, "* %s%s\n", strings.TrimSpace(accomplishment.Summary), prReferences(accomplishment.PullRequests))
+			}
+		}
+	}
+
+	if len(r.WorkInProgress) > 0 {
+		b.WriteString("\n## 🚧 Work in Progress\n")
+		for _, item := range r.WorkInProgress {
+			summary := strings.TrimSpace(item.Summary)
+			if item.Repository != "" {
+				summary = item.Repository + ": " + summary
+			}
+			fmt.Fprintf(&b, "* %s%s\n", summary, prReferences(item.PullRequests))
+		}
+	}
+
+	return b.String()
+}
+
+// prReferences formats PR numbers as a trailing " (#1, #2)"
+func prReferences(numbers []int) string {
+	if len(numbers) == 0 {
+		return ""
+	}
+	refs := make([]string, len(numbers))
+	for i, number := range numbers {
+		refs[i] = fmt.Sprintf("#%d", number)
+	}
+	return " (" + strings.Join(refs, ", ") + ")"
+}
@@ -0,0 +1,246 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"git-log/internal/processing"
+)
+
+// PRSummarySystemPrompt asks for the impact statement of a single pull request
+const PRSummarySystemPrompt = `You are a senior software engineer summarizing a single pull request for a developer's accomplishment log.
+
+You are given the pull request as JSON: its title, description, state, diffstat and commit messages.
+
+Write one or two sentences, at most 60 words, stating what the change achieved and why it matters, starting with a past-tense action verb (e.g., "Added", "Fixed", "Migrated"). Mention the key technologies involved. If the pull request was closed without merging or is still open, say so.
+
+Do not mention the PR number, the diffstat or the author. Output only the summary, with no preamble, quotes or Markdown.`
+
+// PRSummary is the cached impact statement of a single pull request
+type PRSummary struct {
+	ID         int64     `json:"id"`
+	Repository string    `json:"repository"`
+	Number     int       `json:"number"`
+	Title      string    `json:"title"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Summary    string    `json:"summary"`
+	// CommitSHAs are the PR's commits, so they can be left out of later reports without fetching them again
+	CommitSHAs []string `json:"commit_shas,omitempty"`
+	// Incomplete summaries were made without the PR's diffstat and commits, because they couldn't
+	// be fetched. They are made again once the details are available.
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// SummaryStore keeps PR summaries on disk, one readable JSON file per pull request,
+// so they are only generated again when the PR changes
+type SummaryStore struct {
+	Dir string
+}
+
+func NewSummaryStore(dir string) *SummaryStore {
+	return &SummaryStore{Dir: dir}
+}
+
+// Has reports whether the store has a complete summary of this version of the PR
+func (s *SummaryStore) Has(repository string, pr processing.PullRequest) bool {
+	summary, ok := s.get(repository, pr)
+	return ok && !summary.Incomplete
+}
+
+// get returns the stored summary if it was made from this version of the PR
+func (s *SummaryStore) get(repository string, pr processing.PullRequest) (PRSummary, bool) {
+	data, err := os.ReadFile(s.path(repository, pr.Number))
+	if err != nil {
+		return PRSummary{}, false
+	}
+
+	var summary PRSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return PRSummary{}, false
+	}
+	if summary.ID != pr.ID || !summary.UpdatedAt.Equal(pr.UpdatedAt) {
+		return PRSummary{}, false
+	}
+	return summary, true
+}
+
+// put writes the summary to disk
+func (s *SummaryStore) put(summary PRSummary) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("creating summary directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := s.path(summary.Repository, summary.Number)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing summary: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// path names the file after the repository and PR number so summaries are easy to find
+func (s *SummaryStore) path(repository string, number int) string {
+	name := strings.ReplaceAll(repository, "/", "__")
+	return filepath.Join(s.Dir, fmt.Sprintf("%s-%d.json", name, number))
+}
+
+// prSummaryInput is what the model sees of a pull request
+type prSummaryInput struct {
+	Repository     string   `json:"repository"`
+	Title          string   `json:"title"`
+	Body           string   `json:"body,omitempty"`
+	State          string   `json:"state"`
+	Merged         bool     `json:"merged"`
+	Additions      int      `json:"additions,omitempty"`
+	Deletions      int      `json:"deletions,omitempty"`
+	ChangedFiles   int      `json:"changed_files,omitempty"`
+	CommitMessages []string `json:"commit_messages,omitempty"`
+}
+
+// SummarizePullRequests replaces the description of every authored pull request with a short
+// impact statement, generated once per PR version and kept in the store. Commits that belong
+// to a pull request are dropped since its summary already covers them, the rest keep their
+// subject line, and reviews keep only their titles. The input is left untouched.
+func SummarizePullRequests(ctx context.Context, provider Provider, workLog processing.WorkLog, store *SummaryStore, opts GenerateOptions) (processing.WorkLog, error) {
+	generated, cached := 0, 0
+	summarized, err := applySummaries(workLog, func(repository string, pr processing.PullRequest) (PRSummary, bool, error) {
+		// An incomplete summary is only replaced when the details it lacked have been fetched
+		if summary, ok := store.get(repository, pr); ok && (!summary.Incomplete || !hasDetails(pr)) {
+			cached++
+			return summary, true, nil
+		}
+
+		text, err := summarizePullRequest(ctx, provider, repository, pr, opts)
+		if err != nil {
+			return PRSummary{}, false, fmt.Errorf("summarizing %s#%d: %w", repository, pr.Number, err)
+		}
+		generated++
+
+		summary := PRSummary{
+			ID:         pr.ID,
+			Repository: repository,
+			Number:     pr.Number,
+			Title:      pr.Title,
+			UpdatedAt:  pr.UpdatedAt,
+			Summary:    text,
+			CommitSHAs: pr.CommitSHAs,
+			Incomplete: !hasDetails(pr),
+		}
+		return summary, true, store.put(summary)
+	})
+	if err != nil {
+		return workLog, err
+	}
+
+	fmt.Printf("Summarized %d pull requests (%d from cache)\n", generated+cached, cached)
+	return summarized, nil
+}
+
+// hasDetails reports whether EnrichPullRequests filled in the PR's diffstat and commits
+func hasDetails(pr processing.PullRequest) bool {
+	return pr.CommitSHAs != nil
+}
+
+// ApplyCachedSummaries is SummarizePullRequests without the provider: only summaries already
+// in the store are used, and pull requests without one keep their description. It returns
+// how many pull requests had no summary.
+func ApplyCachedSummaries(workLog processing.WorkLog, store *SummaryStore) (processing.WorkLog, int) {
+	missing := 0
+	summarized, _ := applySummaries(workLog, func(repository string, pr processing.PullRequest) (PRSummary, bool, error) {
+		summary, ok := store.get(repository, pr)
+		if !ok {
+			missing++
+		}
+		return summary, ok, nil
+	})
+	return summarized, missing
+}
+
+// applySummaries condenses the work log, using summaryFor to look up each pull request's summary
+func applySummaries(workLog processing.WorkLog, summaryFor func(repository string, pr processing.PullRequest) (PRSummary, bool, error)) (processing.WorkLog, error) {
+	summarized := workLog
+	summarized.Repositories = make([]processing.RepositoryActivity, len(workLog.Repositories))
+
+	for i, repo := range workLog.Repositories {
+		// Commits are matched by SHA, since subjects such as "fix tests" repeat across unrelated work
+		inPullRequest := make(map[string]bool)
+		prs := make([]processing.PullRequest, len(repo.PullRequests))
+		for j, pr := range repo.PullRequests {
+			summary, ok, err := summaryFor(repo.FullName, pr)
+			if err != nil {
+				return workLog, err
+			}
+			if !ok {
+				prs[j] = pr
+				continue
+			}
+
+			for _, sha := range summary.CommitSHAs {
+				inPullRequest[sha] = true
+			}
+
+			pr.Body = summary.Summary
+			pr.CommitMessages = nil
+			pr.CommitSHAs = nil
+			prs[j] = pr
+		}
+
+		reviews := make([]processing.PullRequest, len(repo.Reviews))
+		for j, pr := range repo.Reviews {
+			pr.Body = ""
+			reviews[j] = pr
+		}
+
+		var commits []processing.Commit
+		for _, commit := range repo.Commits {
+			subject, _, _ := strings.Cut(commit.Message, "\n")
+			if !inPullRequest[commit.SHA] {
+				commit.Message = subject
+				commits = append(commits, commit)
+			}
+		}
+
+		repo.PullRequests = prs
+		repo.Reviews = reviews
+		repo.Commits = commits
+		summarized.Repositories[i] = repo
+	}
+
+	return summarized, nil
+}
+
+// summarizePullRequest asks the model for the impact statement of one pull request
+func summarizePullRequest(ctx context.Context, provider Provider, repository string, pr processing.PullRequest, opts GenerateOptions) (string, error) {
+	body := condenseBodies([]processing.PullRequest{pr}, 4000)[0].Body
+
+	input, err := json.Marshal(prSummaryInput{
+		Repository:     repository,
+		Title:          pr.Title,
+		Body:           body,
+		State:          pr.State,
+		Merged:         pr.MergedAt != nil,
+		Additions:      pr.Additions,
+		Deletions:      pr.Deletions,
+		ChangedFiles:   pr.ChangedFiles,
+		CommitMessages: pr.CommitMessages,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	summary, err := provider.Generate(ctx, PRSummarySystemPrompt, string(input), opts)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(summary), nil
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git-log/internal/processing"
+)
+
+// countingProvider returns a fixed summary and counts how often it was asked for one
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Generate(context.Context, string, string, GenerateOptions) (string, error) {
+	p.calls++
+	return "Added login.", nil
+}
+
+func TestSummarizePullRequestsRefreshesIncompleteSummaries(t *testing.T) {
+	store := NewSummaryStore(t.TempDir())
+	pr := processing.PullRequest{ID: 1, Number: 12, Title: "Add login", UpdatedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	enriched := pr
+	enriched.CommitSHAs = []string{"abc123"}
+	enriched.CommitMessages = []string{"Add login"}
+
+	tests := []struct {
+		name          string
+		pr            processing.PullRequest
+		wantCalls     int
+		wantComplete  bool
+		wantCommitSHA bool
+	}{
+		// Each step runs against the store the previous one left behind
+		{name: "details couldn't be fetched", pr: pr, wantCalls: 1},
+		{name: "details still missing", pr: pr, wantCalls: 0},
+		{name: "details fetched", pr: enriched, wantCalls: 1, wantComplete: true, wantCommitSHA: true},
+		{name: "complete summary is reused", pr: enriched, wantCalls: 0, wantComplete: true, wantCommitSHA: true},
+		{name: "replayed without details", pr: pr, wantCalls: 0, wantComplete: true, wantCommitSHA: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workLog := processing.WorkLog{Repositories: []processing.RepositoryActivity{{
+				Name:         "api",
+				FullName:     "acme/api",
+				PullRequests: []processing.PullRequest{tt.pr},
+			}}}
+
+			provider := &countingProvider{}
+			if _, err := SummarizePullRequests(context.Background(), provider, workLog, store, GenerateOptions{}); err != nil {
+				t.Fatalf("SummarizePullRequests() error = %v", err)
+			}
+			if provider.calls != tt.wantCalls {
+				t.Errorf("generated %d summaries, want %d", provider.calls, tt.wantCalls)
+			}
+			if got := store.Has("acme/api", tt.pr); got != tt.wantComplete {
+				t.Errorf("Has() = %v, want %v", got, tt.wantComplete)
+			}
+			summary, ok := store.get("acme/api", tt.pr)
+			if !ok {
+				t.Fatal("no summary was stored")
+			}
+			if got := len(summary.CommitSHAs) > 0; got != tt.wantCommitSHA {
+				t.Errorf("stored commit SHAs = %v, want some: %v", summary.CommitSHAs, tt.wantCommitSHA)
+			}
+		})
+	}
+}
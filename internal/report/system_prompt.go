@@ -12,7 +12,7 @@ Analyze Inputs: You will be given:
 
 EXISTING_REPORT.MD: The complete, existing accomplishment report. This may be empty if this is the first run.
 
-WORK_LOG.JSON: A JSON object containing all pull requests and associated commits from the last 30 days.
+WORK_LOG.JSON: A JSON object containing all pull requests and associated commits from the reporting period given in the user message.
 
 Primary Goal: Merge & Synthesize
 Your main task is to process every item in WORK_LOG.JSON and integrate it into the EXISTING_REPORT.MD. For each PR and its commits:
@@ -45,6 +45,10 @@ Add WIP Items: Place a summary of this preliminary work in this section (e.g., "
 
 Promote from WIP: If a PR already exists in the ## 🚧 Work in Progress section of the EXISTING_REPORT.MD, and the new WORK_LOG.JSON shows significant updates (more commits, new description, merge), you must move it from the WIP section to its proper place under its repository and a newly generated feature title.
 
+Code Reviews:
+
+WORK_LOG.JSON may also contain a "reviews" list per repository: pull requests authored by other people that the developer reviewed. These are not the developer's own work. Do not list them as accomplishments of their own; instead, where review work is substantial, summarize it in a single bullet under the repository (e.g., "Reviewed 12 pull requests across the billing and auth modules, including the Stripe migration (#210)").
+
 Synthesis is Key:
 
 Do not be a raw logger. Do not just list every commit.
@@ -0,0 +1,142 @@
+package report
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"git-log/internal/processing"
+)
+
+//go:embed templates/default.md.tmpl
+var DefaultTemplate string
+
+// Pull request categories, in the order they are rendered
+const (
+	CategoryMerged     = "Merged"
+	CategoryInProgress = "In Progress"
+	CategoryClosed     = "Closed Without Merging"
+)
+
+// TemplateData is what report templates are executed with
+type TemplateData struct {
+	Profile      Profile
+	Period       processing.DateRange
+	Summary      processing.Summary
+	Repositories []TemplateRepository
+}
+
+// TemplateRepository is a repository's activity with its pull requests grouped by category
+type TemplateRepository struct {
+	processing.RepositoryActivity
+	Categories []TemplateCategory
+}
+
+// TemplateCategory is a named group of pull requests
+type TemplateCategory struct {
+	Name         string
+	PullRequests []processing.PullRequest
+}
+
+var templateFuncs = template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.Format("Jan 2, 2006")
+	},
+	"firstLine": func(s string) string {
+		line, _, _ := strings.Cut(s, "\n")
+		return line
+	},
+	"shortSHA": func(sha string) string {
+		if len(sha) > 7 {
+			return sha[:7]
+		}
+		return sha
+	},
+	"join": strings.Join,
+}
+
+// RenderTemplate renders the work log to Markdown without an LLM. It uses the template
+// at templatePath, or the built-in default when templatePath is empty. The output only
+// depends on the work log and the profile, so it is reproducible.
+func RenderTemplate(workLog processing.WorkLog, profile Profile, templatePath string) (string, error) {
+	text := DefaultTemplate
+	name := "default"
+	if templatePath != "" {
+		templateBytes, err := os.ReadFile(templatePath)
+		if err != nil {
+			return "", fmt.Errorf("reading template: %w", err)
+		}
+		text = string(templateBytes)
+		name = templatePath
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, NewTemplateData(workLog, profile)); err != nil {
+		return "", fmt.Errorf("rendering template: %w", err)
+	}
+
+	return strings.TrimSpace(buf.String()) + "\n", nil
+}
+
+// NewTemplateData groups each repository's pull requests into categories
+func NewTemplateData(workLog processing.WorkLog, profile Profile) TemplateData {
+	data := TemplateData{
+		Profile:      profile,
+		Period:       workLog.Summary.Period,
+		Summary:      workLog.Summary,
+		Repositories: make([]TemplateRepository, 0, len(workLog.Repositories)),
+	}
+
+	// Saved work logs may predate the period field
+	if data.Period.Start.IsZero() {
+		data.Period = workLog.Summary.DateRange
+	}
+
+	for _, repo := range workLog.Repositories {
+		byCategory := make(map[string][]processing.PullRequest)
+		for _, pr := range repo.PullRequests {
+			category := pullRequestCategory(pr)
+			byCategory[category] = append(byCategory[category], pr)
+		}
+
+		templateRepo := TemplateRepository{RepositoryActivity: repo}
+		for _, name := range []string{CategoryMerged, CategoryInProgress, CategoryClosed} {
+			if prs := byCategory[name]; len(prs) > 0 {
+				templateRepo.Categories = append(templateRepo.Categories, TemplateCategory{Name: name, PullRequests: prs})
+			}
+		}
+		data.Repositories = append(data.Repositories, templateRepo)
+	}
+
+	return data
+}
+
+// AppendRendered merges a rendered report into the existing one, so profiles that accumulate
+// history keep earlier windows. See Document.Append.
+func AppendRendered(existing, rendered string, workLog processing.WorkLog) string {
+	repoNames := make([]string, 0, len(workLog.Repositories))
+	for _, repo := range workLog.Repositories {
+		repoNames = append(repoNames, repo.Name)
+	}
+	return ParseDocument(existing).Append(ParseDocument(rendered), repoNames).String()
+}
+
+func pullRequestCategory(pr processing.PullRequest) string {
+	switch {
+	case pr.MergedAt != nil:
+		return CategoryMerged
+	case pr.State == "closed":
+		return CategoryClosed
+	default:
+		return CategoryInProgress
+	}
+}
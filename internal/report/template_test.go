@@ -0,0 +1,72 @@
+package report
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git-log/internal/processing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func TestRenderTemplateGolden(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, time.March, d, 12, 0, 0, 0, time.UTC)
+	}
+	merged := day(4)
+	closed := day(6)
+
+	workLog := processing.WorkLog{
+		Repositories: []processing.RepositoryActivity{
+			{
+				Name:        "api",
+				FullName:    "acme/api",
+				Description: "The public API.",
+				PullRequests: []processing.PullRequest{
+					{Number: 12, Title: "Add login", State: "closed", MergedAt: &merged, URL: "https://github.com/acme/api/pull/12", Labels: []string{"auth", "feature"}},
+					{Number: 14, Title: "Rate limiting", State: "open", URL: "https://github.com/acme/api/pull/14"},
+					{Number: 15, Title: "Try a new ORM", State: "closed", ClosedAt: &closed, URL: "https://github.com/acme/api/pull/15"},
+				},
+				Commits: []processing.Commit{
+					{SHA: "abc1234def5678", Message: "Fix the build\n\nThe linter changed.", Date: day(3), URL: "https://github.com/acme/api/commit/abc1234def5678"},
+				},
+			},
+			{
+				Name:     "web",
+				FullName: "acme/web",
+				Reviews: []processing.PullRequest{
+					{Number: 7, Title: "Dark mode", State: "open", URL: "https://github.com/acme/web/pull/7"},
+				},
+			},
+		},
+		Summary: processing.Summary{
+			TotalRepositories: 2,
+			TotalPullRequests: 3,
+			TotalCommits:      1,
+			TotalReviews:      1,
+			Period:            processing.DateRange{Start: day(1), End: day(31)},
+		},
+	}
+
+	got, err := RenderTemplate(workLog, Profiles["log"], "")
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+
+	golden := filepath.Join("testdata", "default.md.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("RenderTemplate() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"git-log/internal/processing"
+)
+
+// ContextLimited is implemented by providers whose context window is small enough
+// that the work log may need trimming to fit
+type ContextLimited interface {
+	ContextWindow() int
+}
+
+// EstimateTokens approximates the token count of text at roughly four characters per token
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// trimSteps progressively drop detail from a work log, least valuable first
+var trimSteps = []func(*processing.WorkLog){
+	func(w *processing.WorkLog) { truncateBodies(w, 1000) },
+	func(w *processing.WorkLog) { truncateBodies(w, 300) },
+	func(w *processing.WorkLog) { firstLineCommits(w) },
+	func(w *processing.WorkLog) { truncateBodies(w, 0) },
+	func(w *processing.WorkLog) { dropReviews(w) },
+	func(w *processing.WorkLog) { dropCommits(w) },
+}
+
+// TrimWorkLog removes detail from the work log until its JSON fits in maxTokens, counted the
+// way the provider counts them. PR bodies are shortened first, then commit messages, reviews
+// and finally commits. It returns the trimmed copy and whether it fits; the input is left untouched.
+func TrimWorkLog(provider Provider, workLog processing.WorkLog, maxTokens int) (processing.WorkLog, bool, error) {
+	trimmed, err := copyWorkLog(workLog)
+	if err != nil {
+		return workLog, false, err
+	}
+
+	for _, step := range trimSteps {
+		fits, err := workLogFits(provider, trimmed, maxTokens)
+		if err != nil || fits {
+			return trimmed, fits, err
+		}
+		step(&trimmed)
+	}
+
+	fits, err := workLogFits(provider, trimmed, maxTokens)
+	return trimmed, fits, err
+}
+
+func workLogFits(provider Provider, workLog processing.WorkLog, maxTokens int) (bool, error) {
+	logBytes, err := json.Marshal(workLog)
+	if err != nil {
+		return false, err
+	}
+	return estimateTokens(provider, string(logBytes)) <= maxTokens, nil
+}
+
+// copyWorkLog deep-copies the work log so trimming never mutates the caller's slices
+func copyWorkLog(workLog processing.WorkLog) (processing.WorkLog, error) {
+	var copied processing.WorkLog
+	logBytes, err := json.Marshal(workLog)
+	if err != nil {
+		return copied, err
+	}
+	err = json.Unmarshal(logBytes, &copied)
+	return copied, err
+}
+
+func truncateBodies(w *processing.WorkLog, limit int) {
+	for i := range w.Repositories {
+		repo := &w.Repositories[i]
+		for j := range repo.PullRequests {
+			repo.PullRequests[j].Body = truncate(repo.PullRequests[j].Body, limit)
+		}
+		for j := range repo.Reviews {
+			repo.Reviews[j].Body = truncate(repo.Reviews[j].Body, limit)
+		}
+	}
+}
+
+func firstLineCommits(w *processing.WorkLog) {
+	for i := range w.Repositories {
+		repo := &w.Repositories[i]
+		for j := range repo.Commits {
+			message, _, _ := strings.Cut(repo.Commits[j].Message, "\n")
+			repo.Commits[j].Message = message
+		}
+	}
+}
+
+func dropReviews(w *processing.WorkLog) {
+	for i := range w.Repositories {
+		w.Repositories[i].Reviews = nil
+	}
+}
+
+func dropCommits(w *processing.WorkLog) {
+	for i := range w.Repositories {
+		w.Repositories[i].Commits = nil
+	}
+}
+
+// truncate shortens s to at most limit bytes without splitting a UTF-8 character
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	if limit == 0 {
+		return ""
+	}
+	return s[:limit] + "…"
+}
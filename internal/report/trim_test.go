@@ -0,0 +1,62 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"git-log/internal/processing"
+)
+
+// plainProvider counts tokens with the generic estimate
+type plainProvider struct{}
+
+func (plainProvider) Generate(context.Context, string, string, GenerateOptions) (string, error) {
+	return "", nil
+}
+
+// denseProvider's tokenizer spends a token on every character
+type denseProvider struct{ plainProvider }
+
+func (denseProvider) EstimateTokens(text string) int { return len(text) }
+
+func TestTrimWorkLogCountsTokensLikeTheProvider(t *testing.T) {
+	workLog := processing.WorkLog{Repositories: []processing.RepositoryActivity{{
+		Name:         "api",
+		PullRequests: []processing.PullRequest{{Number: 1, Title: "Login", Body: strings.Repeat("b", 2000)}},
+	}}}
+	logBytes, err := json.Marshal(workLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Enough at four characters per token, not at one
+	maxTokens := EstimateTokens(string(logBytes))
+
+	tests := []struct {
+		name     string
+		provider Provider
+		wantBody int
+	}{
+		{name: "generic estimate", provider: plainProvider{}, wantBody: 2000},
+		{name: "provider estimate", provider: denseProvider{}, wantBody: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trimmed, fits, err := TrimWorkLog(tt.provider, workLog, maxTokens)
+			if err != nil {
+				t.Fatalf("TrimWorkLog() error = %v", err)
+			}
+			if !fits {
+				t.Fatal("TrimWorkLog() fits = false, want true")
+			}
+			if got := len(trimmed.Repositories[0].PullRequests[0].Body); got != tt.wantBody {
+				t.Errorf("body length = %d, want %d", got, tt.wantBody)
+			}
+		})
+	}
+	if len(workLog.Repositories[0].PullRequests[0].Body) != 2000 {
+		t.Error("TrimWorkLog() changed the input")
+	}
+}
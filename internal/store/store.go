@@ -0,0 +1,239 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"git-log/internal/github"
+)
+
+// Sources tracked by the store
+const (
+	SourcePullRequests = "pull_requests"
+	SourceReviews      = "reviews"
+	SourceCommits      = "commits"
+)
+
+const stateFile = "state.json"
+
+// Store persists fetched GitHub activity as JSON lines so later runs only need to fetch deltas
+type Store struct {
+	dir          string
+	pullRequests map[int64]github.IssueSearchResultItem
+	reviews      map[int64]github.IssueSearchResultItem
+	commits      map[string]github.CommitSearchResultItem
+	state        State
+}
+
+// State records which part of history each source covers
+type State struct {
+	Sources map[string]SourceState `json:"sources"`
+}
+
+// SourceState is the window a source has been synced for
+type SourceState struct {
+	// CoveredFrom is the earliest time the source has been fully fetched from
+	CoveredFrom time.Time `json:"covered_from"`
+	// LastSync is the end of the window fetched by the last successful sync
+	LastSync time.Time `json:"last_sync"`
+}
+
+// Open loads the store in dir, creating it if it doesn't exist
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating store directory: %w", err)
+	}
+
+	s := &Store{
+		dir:          dir,
+		pullRequests: make(map[int64]github.IssueSearchResultItem),
+		reviews:      make(map[int64]github.IssueSearchResultItem),
+		commits:      make(map[string]github.CommitSearchResultItem),
+		state:        State{Sources: make(map[string]SourceState)},
+	}
+
+	if err := readLines(s.path(SourcePullRequests), func(item github.IssueSearchResultItem) {
+		s.pullRequests[item.ID] = item
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := readLines(s.path(SourceReviews), func(item github.IssueSearchResultItem) {
+		s.reviews[item.ID] = item
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := readLines(s.path(SourceCommits), func(item github.CommitSearchResultItem) {
+		s.commits[item.SHA] = item
+	}); err != nil {
+		return nil, err
+	}
+
+	stateBytes, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading store state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(stateBytes, &s.state); err != nil {
+			return nil, fmt.Errorf("parsing store state: %w", err)
+		}
+		if s.state.Sources == nil {
+			s.state.Sources = make(map[string]SourceState)
+		}
+	}
+
+	return s, nil
+}
+
+// Save writes all records and the sync state back to disk
+func (s *Store) Save() error {
+	if err := writeLines(s.path(SourcePullRequests), sortedPullRequests(s.pullRequests)); err != nil {
+		return err
+	}
+	if err := writeLines(s.path(SourceReviews), sortedPullRequests(s.reviews)); err != nil {
+		return err
+	}
+
+	commits := make([]github.CommitSearchResultItem, 0, len(s.commits))
+	for _, commit := range s.commits {
+		commits = append(commits, commit)
+	}
+	sort.Slice(commits, func(i, j int) bool {
+		return commits[i].SHA < commits[j].SHA
+	})
+	if err := writeLines(s.path(SourceCommits), commits); err != nil {
+		return err
+	}
+
+	stateBytes, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.dir, stateFile), stateBytes)
+}
+
+// SourceState returns the sync state of a source and whether it has been synced before
+func (s *Store) SourceState(source string) (SourceState, bool) {
+	state, ok := s.state.Sources[source]
+	return state, ok
+}
+
+// PullRequests returns the stored pull requests created within the window
+func (s *Store) PullRequests(since, until time.Time) []github.IssueSearchResultItem {
+	var items []github.IssueSearchResultItem
+	for _, item := range sortedPullRequests(s.pullRequests) {
+		if within(item.CreatedAt, since, until) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Reviews returns the stored reviewed pull requests updated within the window
+func (s *Store) Reviews(since, until time.Time) []github.IssueSearchResultItem {
+	var items []github.IssueSearchResultItem
+	for _, item := range sortedPullRequests(s.reviews) {
+		if within(item.UpdatedAt, since, until) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Commits returns the stored commits authored within the window
+func (s *Store) Commits(since, until time.Time) []github.CommitSearchResultItem {
+	var items []github.CommitSearchResultItem
+	for _, item := range s.commits {
+		date, err := time.Parse(time.RFC3339, item.Commit.Author.Date)
+		if err != nil || !within(date, since, until) {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].SHA < items[j].SHA
+	})
+	return items
+}
+
+func (s *Store) path(source string) string {
+	return filepath.Join(s.dir, source+".jsonl")
+}
+
+// within reports whether t falls in the inclusive window
+func within(t, since, until time.Time) bool {
+	return !t.Before(since) && !t.After(until)
+}
+
+func sortedPullRequests(m map[int64]github.IssueSearchResultItem) []github.IssueSearchResultItem {
+	items := make([]github.IssueSearchResultItem, 0, len(m))
+	for _, item := range m {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+	return items
+}
+
+// readLines decodes every line of a JSON lines file. A missing file is treated as empty.
+func readLines[T any](path string, fn func(T)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// PR bodies can easily exceed the default 64KB line limit
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var item T
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			return fmt.Errorf("parsing %s line %d: %w", path, line, err)
+		}
+		fn(item)
+	}
+
+	return scanner.Err()
+}
+
+// writeLines encodes items as JSON lines, replacing the file atomically
+func writeLines[T any](path string, items []T) error {
+	var buf []byte
+	for _, item := range items {
+		line, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	return writeFileAtomic(path, buf)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replacing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"git-log/internal/github"
+)
+
+// syncOverlap is re-fetched before the last sync to allow for GitHub's search index lag
+const syncOverlap = time.Hour
+
+// SyncPullRequests fetches the user's pull requests for the window that are not already in the store.
+// Once history is covered, only PRs updated since the last sync are fetched so state changes are picked up.
+// It returns the number of new pull requests.
+func (s *Store) SyncPullRequests(ctx context.Context, client *github.Client, username string, since, until time.Time) (int, error) {
+	return syncSource(s, SourcePullRequests, since, until,
+		func(from, to time.Time, full bool) ([]github.IssueSearchResultItem, error) {
+			if full {
+				return client.GetPullRequests(ctx, username, from, to)
+			}
+			return client.GetUpdatedPullRequests(ctx, username, from, to)
+		},
+		func(item github.IssueSearchResultItem) bool {
+			_, exists := s.pullRequests[item.ID]
+			s.pullRequests[item.ID] = item
+			return !exists
+		})
+}
+
+// SyncReviews fetches pull requests reviewed by the user for the window that are not already in the store.
+// It returns the number of new reviewed pull requests.
+func (s *Store) SyncReviews(ctx context.Context, client *github.Client, username string, since, until time.Time) (int, error) {
+	return syncSource(s, SourceReviews, since, until,
+		func(from, to time.Time, full bool) ([]github.IssueSearchResultItem, error) {
+			return client.GetReviewedPullRequests(ctx, username, from, to)
+		},
+		func(item github.IssueSearchResultItem) bool {
+			_, exists := s.reviews[item.ID]
+			s.reviews[item.ID] = item
+			return !exists
+		})
+}
+
+// SyncCommits fetches the user's commits for the window that are not already in the store.
+// Deltas are fetched by committer date so late pushes of older commits are not missed.
+// It returns the number of new commits.
+func (s *Store) SyncCommits(ctx context.Context, client *github.Client, username string, since, until time.Time) (int, error) {
+	return syncSource(s, SourceCommits, since, until,
+		func(from, to time.Time, full bool) ([]github.CommitSearchResultItem, error) {
+			if full {
+				return client.GetCommits(ctx, username, from, to)
+			}
+			return client.GetCommittedCommits(ctx, username, from, to)
+		},
+		func(item github.CommitSearchResultItem) bool {
+			_, exists := s.commits[item.SHA]
+			s.commits[item.SHA] = item
+			return !exists
+		})
+}
+
+// syncSource fetches whatever part of the window the source doesn't cover yet, upserts
+// the results and advances the source's sync state.
+func syncSource[T any](s *Store, source string, since, until time.Time,
+	fetch func(from, to time.Time, full bool) ([]T, error), upsert func(T) bool) (int, error) {
+
+	state, synced := s.state.Sources[source]
+
+	from, full := since, true
+	if synced && !since.Before(state.CoveredFrom) {
+		// Nothing new to fetch if the window ends before the last sync
+		if !until.After(state.LastSync) {
+			return 0, nil
+		}
+		from, full = state.LastSync.Add(-syncOverlap), false
+	}
+
+	items, err := fetch(from, until, full)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, item := range items {
+		if upsert(item) {
+			added++
+		}
+	}
+
+	switch {
+	case !synced:
+		state = SourceState{CoveredFrom: since, LastSync: until}
+	case !full:
+		state.LastSync = until
+	case !until.Before(state.CoveredFrom):
+		// A backfill that connects to the covered history extends it
+		state.CoveredFrom = since
+		if until.After(state.LastSync) {
+			state.LastSync = until
+		}
+	}
+	s.state.Sources[source] = state
+
+	return added, nil
+}
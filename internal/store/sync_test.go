@@ -0,0 +1,155 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSyncSource(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	type call struct {
+		from, to time.Time
+		full     bool
+	}
+
+	tests := []struct {
+		name         string
+		state        *SourceState
+		since, until time.Time
+		wantCall     *call
+		wantState    SourceState
+	}{
+		{
+			name:      "first sync fetches the whole window",
+			since:     day(time.January, 1),
+			until:     day(time.March, 1),
+			wantCall:  &call{from: day(time.January, 1), to: day(time.March, 1), full: true},
+			wantState: SourceState{CoveredFrom: day(time.January, 1), LastSync: day(time.March, 1)},
+		},
+		{
+			name:      "incremental sync fetches since the last sync with an overlap",
+			state:     &SourceState{CoveredFrom: day(time.January, 1), LastSync: day(time.March, 1)},
+			since:     day(time.February, 1),
+			until:     day(time.April, 1),
+			wantCall:  &call{from: day(time.March, 1).Add(-syncOverlap), to: day(time.April, 1), full: false},
+			wantState: SourceState{CoveredFrom: day(time.January, 1), LastSync: day(time.April, 1)},
+		},
+		{
+			name:      "covered window is not fetched again",
+			state:     &SourceState{CoveredFrom: day(time.January, 1), LastSync: day(time.March, 1)},
+			since:     day(time.February, 1),
+			until:     day(time.February, 15),
+			wantState: SourceState{CoveredFrom: day(time.January, 1), LastSync: day(time.March, 1)},
+		},
+		{
+			name:      "backfill connecting to the covered history extends it",
+			state:     &SourceState{CoveredFrom: day(time.March, 1), LastSync: day(time.April, 1)},
+			since:     day(time.January, 1),
+			until:     day(time.March, 15),
+			wantCall:  &call{from: day(time.January, 1), to: day(time.March, 15), full: true},
+			wantState: SourceState{CoveredFrom: day(time.January, 1), LastSync: day(time.April, 1)},
+		},
+		{
+			name:      "backfill past the last sync moves both ends",
+			state:     &SourceState{CoveredFrom: day(time.March, 1), LastSync: day(time.April, 1)},
+			since:     day(time.January, 1),
+			until:     day(time.May, 1),
+			wantCall:  &call{from: day(time.January, 1), to: day(time.May, 1), full: true},
+			wantState: SourceState{CoveredFrom: day(time.January, 1), LastSync: day(time.May, 1)},
+		},
+		{
+			name:      "backfill leaving a gap doesn't extend the covered history",
+			state:     &SourceState{CoveredFrom: day(time.March, 1), LastSync: day(time.April, 1)},
+			since:     day(time.January, 1),
+			until:     day(time.February, 1),
+			wantCall:  &call{from: day(time.January, 1), to: day(time.February, 1), full: true},
+			wantState: SourceState{CoveredFrom: day(time.March, 1), LastSync: day(time.April, 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Open(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.state != nil {
+				s.state.Sources[SourceCommits] = *tt.state
+			}
+
+			var got *call
+			_, err = syncSource(s, SourceCommits, tt.since, tt.until,
+				func(from, to time.Time, full bool) ([]string, error) {
+					got = &call{from: from, to: to, full: full}
+					return nil, nil
+				},
+				func(string) bool { return true })
+			if err != nil {
+				t.Fatalf("syncSource() error = %v", err)
+			}
+
+			switch {
+			case tt.wantCall == nil && got != nil:
+				t.Errorf("fetched %v..%v, want no fetch", got.from, got.to)
+			case tt.wantCall != nil && got == nil:
+				t.Errorf("no fetch, want %v..%v", tt.wantCall.from, tt.wantCall.to)
+			case tt.wantCall != nil && *got != *tt.wantCall:
+				t.Errorf("fetched %+v, want %+v", *got, *tt.wantCall)
+			}
+
+			if state := s.state.Sources[SourceCommits]; state != tt.wantState {
+				t.Errorf("state = %+v, want %+v", state, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestSyncSourceCountsOnlyNewItems(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The overlap re-fetches items that are already stored
+	seen := map[string]bool{"a": true, "b": true}
+	added, err := syncSource(s, SourceCommits, time.Unix(0, 0), time.Unix(3600, 0),
+		func(from, to time.Time, full bool) ([]string, error) {
+			return []string{"a", "b", "c"}, nil
+		},
+		func(item string) bool {
+			exists := seen[item]
+			seen[item] = true
+			return !exists
+		})
+	if err != nil {
+		t.Fatalf("syncSource() error = %v", err)
+	}
+	if added != 1 {
+		t.Errorf("added = %d, want 1", added)
+	}
+}
+
+func TestSyncSourceKeepsStateOnError(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = syncSource(s, SourceCommits, time.Unix(0, 0), time.Unix(3600, 0),
+		func(from, to time.Time, full bool) ([]string, error) {
+			return nil, errTest
+		},
+		func(string) bool { return true })
+	if !errors.Is(err, errTest) {
+		t.Fatalf("syncSource() error = %v, want %v", err, errTest)
+	}
+	if _, ok := s.state.Sources[SourceCommits]; ok {
+		t.Error("a failed sync marked the window as covered")
+	}
+}
+
+var errTest = errors.New("fetch failed")
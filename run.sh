@@ -6,4 +6,4 @@ if [ -f .env ]; then
 fi
 
 # Run the Go application
-go run ./cmd/main.go
\ No newline at end of file
+go run ./cmd "$@"
\ No newline at end of file